@@ -15,12 +15,98 @@ var (
 	_ = fs.String("crypto.http.addr", ":8000", "Puerto HTTP del servicio")
 	_ = fs.String("crypto.logging.format", "console", "Formato de log: json, console")
 	_ = fs.Duration("crypto.http.shutdown.timeout", 15*time.Second, "HTTP server graceful shutdown timeout")
+	_ = fs.Duration("crypto.shutdown.timeout", 30*time.Second, "Tiempo máximo para detener todos los componentes")
+)
+
+// Proveedores de market data
+var (
+	_ = pflag.StringSlice("crypto.providers", []string{"cryptonator"}, "Proveedores de market data habilitados")
+	_ = fs.String("crypto.pairs.file", "", "Archivo YAML/JSON con los pares y sus opciones; reemplaza a los flags crypto.api.<proveedor>.pairs")
+	_ = fs.String("crypto.aggregator.mode", "failover", "Modo de agregación de precios: failover, consensus")
+	_ = pflag.StringSlice("crypto.aggregator.priority", []string{"cryptonator"}, "Prioridad de proveedores para failover")
+	_ = pflag.StringSlice("crypto.aggregator.symbol.priority", []string{}, "Prioridad por símbolo 'simbolo;proveedor1;proveedor2'")
+	_ = fs.Duration("crypto.aggregator.max.age", time.Minute, "Antigüedad máxima de un precio para considerarlo vigente")
+	_ = fs.Int("crypto.aggregator.min.sources", 2, "Cantidad mínima de proveedores para el modo consensus")
+	_ = fs.Float64("crypto.aggregator.max.deviation", 0.05, "Desvío máximo respecto de la mediana antes de descartar un precio")
+)
+
+// Instrumentos derivados
+var (
+	_ = pflag.StringSlice("crypto.derived.instruments", []string{}, "Instrumentos derivados 'simbolo;cross|inverse|product;leg1[;leg2]'")
+)
+
+// Índices sintéticos
+var (
+	_ = fs.String("crypto.indices.file", "", "Archivo YAML o JSON con la definición de índices")
+	_ = fs.Int("crypto.indices.history.size", 1000, "Cantidad de niveles históricos guardados por índice")
+	_ = fs.Int("crypto.indices.queue.size", 1024, "Tamaño de la cola de ticks pendientes de procesar por los índices")
+)
+
+// Distribución de market data
+var (
+	_ = fs.Int("crypto.marketdata.queue.size", 1024, "Tamaño de la cola de ticks de los proveedores pendientes de almacenar")
+	_ = fs.Int("crypto.marketdata.subscriber.buffer", 100, "Tamaño del buffer de cada suscriptor de market data")
+	_ = fs.String("crypto.marketdata.subscriber.policy", "conflate", "Política ante un suscriptor lento: drop, conflate o disconnect")
+	_ = fs.Duration("crypto.sse.heartbeat.interval", 15*time.Second, "Intervalo de heartbeats de /marketdata/stream (0: sin heartbeats)")
+)
+
+// Valuación de wallets en vivo
+var (
+	_ = fs.Duration("crypto.wallet.live.min.interval", time.Second, "Intervalo mínimo entre valuaciones enviadas por /wallet/live")
+	_ = fs.Duration("crypto.wallet.live.refresh.interval", 30*time.Second, "Intervalo de relectura de la composición de las wallets observadas (0: deshabilitado)")
+	_ = pflag.StringSlice("crypto.wallet.live.allowed.origins", nil, "Orígenes de navegador, además del propio, que pueden conectarse a /wallet/live")
+)
+
+// Snapshot de market data (warm start)
+var (
+	_ = fs.Bool("crypto.snapshot.enabled", true, "Persistir el último precio de cada símbolo y restaurarlo al iniciar")
+	_ = fs.Duration("crypto.snapshot.interval", 10*time.Second, "Intervalo de persistencia del snapshot (0: en cada actualización)")
+)
+
+// Publicación de eventos
+var (
+	_ = fs.String("crypto.events.nats.url", "", "URL del servidor NATS donde se publican precios y cambios de wallets (vacío: deshabilitado)")
+	_ = fs.String("crypto.events.nats.subject.prefix", "", "Prefijo opcional de los subjects md.<simbolo> y wallet.<id>")
+)
+
+// Entrega de eventos de wallets (outbox)
+var (
+	_ = fs.String("crypto.outbox.sink", "log", "Destino de los eventos de wallets: log, webhook o nats (vacío: no se entregan)")
+	_ = fs.Duration("crypto.outbox.interval", time.Second, "Intervalo de lectura de los eventos pendientes")
+	_ = fs.Int("crypto.outbox.batch.size", 100, "Eventos leídos por lectura")
+	_ = fs.Int("crypto.outbox.max.attempts", 10, "Intentos de entrega de un evento antes de descartarlo (0: sin límite)")
+	_ = fs.String("crypto.outbox.webhook.url", "", "URL a la que se envían los eventos con el sink webhook")
+	_ = fs.Duration("crypto.outbox.webhook.timeout", 5*time.Second, "Timeout del webhook de eventos")
+)
+
+// Consumo de cambios de posición
+var (
+	_ = fs.String("crypto.positions.nats.url", "", "URL del servidor NATS (JetStream) del que se consumen los cambios de posición (vacío: deshabilitado)")
+	_ = fs.String("crypto.positions.subject", "positions", "Subject de los cambios de posición")
+	_ = fs.String("crypto.positions.stream", "POSITIONS", "Stream de JetStream; se crea si no existe")
+	_ = fs.String("crypto.positions.durable", "mtz-crypto", "Nombre del consumer durable, común a todas las réplicas")
+	_ = fs.String("crypto.positions.dlq.subject", "dlq.positions", "Subject donde se publican los mensajes que no se pueden aplicar")
+	_ = fs.String("crypto.positions.dlq.stream", "POSITIONS_DLQ", "Stream de JetStream del dead-letter subject (se crea si no existe)")
+	_ = fs.Int("crypto.positions.max.deliver", 5, "Entregas de un mensaje antes de enviarlo al dead-letter subject")
+	_ = fs.Duration("crypto.positions.processed.retention", 7*24*time.Hour, "Tiempo que se recuerdan los mensajes aplicados para no aplicarlos dos veces (0: sin límite)")
+	_ = fs.Duration("crypto.positions.processed.prune.interval", time.Hour, "Intervalo de eliminación de los mensajes aplicados más antiguos que la retención")
+)
+
+// Elección de líder entre réplicas
+var (
+	_ = fs.Bool("crypto.election.enabled", false, "Sólo la réplica líder consulta a los proveedores; el resto lee los precios del snapshot")
+	_ = fs.Int64("crypto.election.key", 7173242, "Clave del advisory lock de PostgreSQL, común a todas las réplicas")
+	_ = fs.Duration("crypto.election.interval", 5*time.Second, "Intervalo para intentar obtener o verificar el liderazgo")
+	_ = fs.Duration("crypto.election.sync.interval", 2*time.Second, "Intervalo de lectura del snapshot en las réplicas que no son líder")
 )
 
 // Cryptonator (API externa)
 var (
 	_ = fs.String("crypto.api.cryptonator.url", "https://api.cryptonator.com/api", "URL API de servicio cryptonator")
 	_ = fs.Duration("crypto.api.cryptonator.poll.interval", 15*time.Second, "Intervalo de consulta")
+	_ = fs.Duration("crypto.api.cryptonator.poll.hot.interval", 5*time.Second, "Intervalo de consulta de los símbolos más referenciados por las wallets")
+	_ = fs.Int("crypto.api.cryptonator.rate.limit", 60, "Requests permitidos por ventana (0: sin límite)")
+	_ = fs.Duration("crypto.api.cryptonator.rate.window", time.Minute, "Ventana del límite de requests")
 	_ = fs.Duration("crypto.api.cryptonator.timeout", 15*time.Second, "Timeout para solicitudes a Cryptonator API")
 	_ = pflag.StringSlice("crypto.api.cryptonator.pairs", []string{
 		"btc-usd;BTCUSD",
@@ -29,6 +115,92 @@ var (
 		"dot-usd;DOTUSD",
 	}, "Pares 'simbolo externo;simbolo interno'")
 	_ = fs.Int("crypto.api.cryptonator.workers", 2, "Número de workers para pedidos concurrentes a la API externa")
+	_ = fs.String("crypto.api.cryptonator.cassette.mode", "", "Grabación del tráfico HTTP: record, replay (vacío para deshabilitar)")
+	_ = fs.String("crypto.api.cryptonator.cassette.file", "cryptonator.cassette.jsonl", "Archivo cassette para grabar o reproducir el tráfico HTTP")
+	_ = fs.Int("crypto.api.cryptonator.retry.attempts", 3, "Intentos por consulta a Cryptonator API, incluido el primero")
+	_ = fs.Duration("crypto.api.cryptonator.retry.min", 200*time.Millisecond, "Espera mínima entre reintentos")
+	_ = fs.Duration("crypto.api.cryptonator.retry.max", 2*time.Second, "Espera máxima entre reintentos")
+	_ = fs.Int("crypto.api.cryptonator.breaker.failures", 5, "Fallas consecutivas que abren el circuit breaker (0: deshabilitado)")
+	_ = fs.Duration("crypto.api.cryptonator.breaker.open.timeout", 30*time.Second, "Tiempo con el circuito abierto antes de volver a probar")
+)
+
+// CoinGecko (API externa)
+var (
+	_ = fs.String("crypto.api.coingecko.url", "https://api.coingecko.com/api/v3", "URL API de servicio CoinGecko")
+	_ = fs.Duration("crypto.api.coingecko.poll.interval", 30*time.Second, "Intervalo de consulta")
+	_ = fs.Duration("crypto.api.coingecko.timeout", 15*time.Second, "Timeout para solicitudes a CoinGecko API")
+	_ = pflag.StringSlice("crypto.api.coingecko.pairs", []string{
+		"bitcoin/usd;BTCUSD",
+		"ethereum/usd;ETHUSD",
+		"cardano/usd;ADAUSD",
+		"polkadot/usd;DOTUSD",
+	}, "Pares 'id/moneda;simbolo interno'")
+	_ = fs.Int("crypto.api.coingecko.batch.size", 50, "Cantidad máxima de ids por request")
+	_ = fs.Int("crypto.api.coingecko.retry.attempts", 3, "Intentos por consulta a CoinGecko API, incluido el primero")
+	_ = fs.Duration("crypto.api.coingecko.retry.min", 200*time.Millisecond, "Espera mínima entre reintentos")
+	_ = fs.Duration("crypto.api.coingecko.retry.max", 2*time.Second, "Espera máxima entre reintentos")
+	_ = fs.Int("crypto.api.coingecko.breaker.failures", 5, "Fallas consecutivas que abren el circuit breaker (0: deshabilitado)")
+	_ = fs.Duration("crypto.api.coingecko.breaker.open.timeout", 30*time.Second, "Tiempo con el circuito abierto antes de volver a probar")
+)
+
+// Binance (WebSocket)
+var (
+	_ = fs.String("crypto.api.binance.url", "wss://stream.binance.com:9443/ws", "URL WebSocket de Binance")
+	_ = fs.String("crypto.api.binance.channel", "trade", "Canal de suscripción: trade, ticker")
+	_ = pflag.StringSlice("crypto.api.binance.pairs", []string{
+		"btcusdt;BTCUSD",
+		"ethusdt;ETHUSD",
+		"adausdt;ADAUSD",
+		"dotusdt;DOTUSD",
+	}, "Pares 'simbolo externo;simbolo interno'")
+	_ = fs.Duration("crypto.api.binance.ping.interval", 20*time.Second, "Intervalo de envío de ping")
+	_ = fs.Duration("crypto.api.binance.pong.timeout", time.Minute, "Tiempo máximo sin mensajes antes de reconectar")
+	_ = fs.Duration("crypto.api.binance.reconnect.min", time.Second, "Espera mínima antes de reconectar")
+	_ = fs.Duration("crypto.api.binance.reconnect.max", time.Minute, "Espera máxima antes de reconectar")
+)
+
+// Replay de archivos de market data (desarrollo offline y backtests)
+var (
+	_ = fs.String("crypto.replay.file", "", "Archivo CSV o JSONL con los ticks a reproducir")
+	_ = fs.Float64("crypto.replay.speed", 1, "Velocidad de reproducción (1: tiempo real, N: acelerada, 0: sin esperas)")
+	_ = fs.Bool("crypto.replay.loop", false, "Volver a reproducir el archivo al llegar al final")
+	_ = fs.Bool("crypto.replay.rebase", false, "Publicar los ticks con la hora de reproducción en lugar de la del archivo")
+)
+
+// Simulador de precios (load tests y demos)
+var (
+	_ = pflag.StringSlice("crypto.simulator.symbols", []string{
+		"BTCUSD;45000;0.05;0.8;0.001;10",
+		"ETHUSD;3000;0.05;0.9;0.001;10",
+	}, "Series 'simbolo;precio inicial;drift;volatilidad;probabilidad de salto;ticks por segundo'")
+	_ = fs.Float64("crypto.simulator.jump.size", 0.05, "Desvío estándar del log-retorno de los saltos")
+	_ = fs.Int64("crypto.simulator.seed", 1, "Semilla para reproducir las series")
+	_ = fs.Int("crypto.simulator.decimals", 8, "Decimales de los precios simulados")
+)
+
+// Administración
+var (
+	_ = fs.String("crypto.admin.token", "", "Token requerido en los endpoints de administración (Authorization: Bearer <token>; vacío: deshabilitados)")
+)
+
+// Health checks
+var (
+	_ = fs.Duration("crypto.health.check.timeout", 2*time.Second, "Timeout de cada verificación de readiness")
+	_ = fs.Duration("crypto.health.max.age", 5*time.Minute, "Antigüedad máxima de los precios para considerar el servicio listo")
+)
+
+// Monitoreo de frescura de la market data
+var (
+	_ = fs.Duration("crypto.freshness.stale.threshold", time.Minute, "Antigüedad a partir de la cual un símbolo se considera desactualizado")
+	_ = fs.Duration("crypto.freshness.check.interval", 10*time.Second, "Intervalo de verificación de símbolos desactualizados")
+	_ = fs.String("crypto.freshness.webhook.url", "", "Webhook de operaciones notificado cuando un símbolo se desactualiza o se recupera")
+	_ = fs.Duration("crypto.freshness.webhook.timeout", 5*time.Second, "Timeout del webhook de operaciones")
+)
+
+// Demanda de símbolos
+var (
+	_ = fs.Duration("crypto.demand.window", 5*time.Minute, "Ventana de conteo de referencias de las wallets a cada símbolo")
+	_ = fs.Int("crypto.demand.hot.threshold", 10, "Referencias en la ventana a partir de las cuales un símbolo es caliente (0: deshabilitado)")
 )
 
 // Cache
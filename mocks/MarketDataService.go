@@ -3,6 +3,9 @@
 package mocks
 
 import (
+	context "context"
+
+	hub "github.com/matbarofex/mtz-crypto/pkg/hub"
 	model "github.com/matbarofex/mtz-crypto/pkg/model"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -17,6 +20,20 @@ func (_m *MarketDataService) ConsumeMD(mdChannel model.MdChannel) {
 	_m.Called(mdChannel)
 }
 
+// Drain provides a mock function with given fields: ctx
+func (_m *MarketDataService) Drain(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetMD provides a mock function with given fields: symbol
 func (_m *MarketDataService) GetMD(symbol string) (model.MarketData, error) {
 	ret := _m.Called(symbol)
@@ -37,3 +54,71 @@ func (_m *MarketDataService) GetMD(symbol string) (model.MarketData, error) {
 
 	return r0, r1
 }
+
+// List provides a mock function with given fields:
+func (_m *MarketDataService) List() ([]model.MarketData, error) {
+	ret := _m.Called()
+
+	var r0 []model.MarketData
+	if rf, ok := ret.Get(0).(func() []model.MarketData); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MarketData)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subscribe provides a mock function with given fields: symbols
+func (_m *MarketDataService) Subscribe(symbols ...string) hub.Subscription {
+	_va := make([]interface{}, len(symbols))
+	for _i := range symbols {
+		_va[_i] = symbols[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 hub.Subscription
+	if rf, ok := ret.Get(0).(func(...string) hub.Subscription); ok {
+		r0 = rf(symbols...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(hub.Subscription)
+		}
+	}
+
+	return r0
+}
+
+// SubscribeAfter provides a mock function with given fields: lastSeq, symbols
+func (_m *MarketDataService) SubscribeAfter(lastSeq uint64, symbols ...string) hub.Subscription {
+	_va := make([]interface{}, len(symbols))
+	for _i := range symbols {
+		_va[_i] = symbols[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, lastSeq)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 hub.Subscription
+	if rf, ok := ret.Get(0).(func(uint64, ...string) hub.Subscription); ok {
+		r0 = rf(lastSeq, symbols...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(hub.Subscription)
+		}
+	}
+
+	return r0
+}
@@ -33,6 +33,29 @@ func (_m *MarketDataStore) GetMD(symbol string) (model.MarketData, error) {
 	return r0, r1
 }
 
+// List provides a mock function with given fields:
+func (_m *MarketDataStore) List() ([]model.MarketData, error) {
+	ret := _m.Called()
+
+	var r0 []model.MarketData
+	if rf, ok := ret.Get(0).(func() []model.MarketData); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MarketData)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetOrUpdateMD provides a mock function with given fields: md
 func (_m *MarketDataStore) SetOrUpdateMD(md model.MarketData) error {
 	ret := _m.Called(md)
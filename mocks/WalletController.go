@@ -16,3 +16,8 @@ type WalletController struct {
 func (_m *WalletController) GetWalletValue(ctx *gin.Context) {
 	_m.Called(ctx)
 }
+
+// GetWalletValueLive provides a mock function with given fields: ctx
+func (_m *WalletController) GetWalletValueLive(ctx *gin.Context) {
+	_m.Called(ctx)
+}
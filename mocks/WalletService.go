@@ -12,6 +12,20 @@ type WalletService struct {
 	mock.Mock
 }
 
+// ApplyPositionChange provides a mock function with given fields: change
+func (_m *WalletService) ApplyPositionChange(change model.PositionChange) error {
+	ret := _m.Called(change)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.PositionChange) error); ok {
+		r0 = rf(change)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetWalletValue provides a mock function with given fields: req
 func (_m *WalletService) GetWalletValue(req model.GetWalletValueRequest) (model.GetWalletValueResponse, error) {
 	ret := _m.Called(req)
@@ -32,3 +46,24 @@ func (_m *WalletService) GetWalletValue(req model.GetWalletValueRequest) (model.
 
 	return r0, r1
 }
+
+// ValueWallet provides a mock function with given fields: walletID
+func (_m *WalletService) ValueWallet(walletID string) (model.GetWalletValueResponse, error) {
+	ret := _m.Called(walletID)
+
+	var r0 model.GetWalletValueResponse
+	if rf, ok := ret.Get(0).(func(string) model.GetWalletValueResponse); ok {
+		r0 = rf(walletID)
+	} else {
+		r0 = ret.Get(0).(model.GetWalletValueResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(walletID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
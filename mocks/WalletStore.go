@@ -12,6 +12,27 @@ type WalletStore struct {
 	mock.Mock
 }
 
+// ApplyPositionChange provides a mock function with given fields: change
+func (_m *WalletStore) ApplyPositionChange(change model.PositionChange) (bool, error) {
+	ret := _m.Called(change)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(model.PositionChange) bool); ok {
+		r0 = rf(change)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(model.PositionChange) error); ok {
+		r1 = rf(change)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetWallet provides a mock function with given fields: id
 func (_m *WalletStore) GetWallet(id string) (model.Wallet, error) {
 	ret := _m.Called(id)
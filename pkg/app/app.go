@@ -0,0 +1,592 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	ginzap "github.com/gin-contrib/zap"
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/pkg"
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/consumer"
+	"github.com/matbarofex/mtz-crypto/pkg/controller"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto/aggregator"
+	"github.com/matbarofex/mtz-crypto/pkg/election"
+	"github.com/matbarofex/mtz-crypto/pkg/events"
+	"github.com/matbarofex/mtz-crypto/pkg/health"
+	"github.com/matbarofex/mtz-crypto/pkg/hub"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/notifier"
+	"github.com/matbarofex/mtz-crypto/pkg/outbox"
+	"github.com/matbarofex/mtz-crypto/pkg/service"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	cacheStore "github.com/matbarofex/mtz-crypto/pkg/store/cache"
+	"github.com/matbarofex/mtz-crypto/pkg/store/db"
+	"github.com/matbarofex/mtz-crypto/pkg/store/memory"
+	"github.com/nats-io/nats.go"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+	ginprom "github.com/zsais/go-gin-prometheus"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// App servicio completo: stores, proveedores, servicios y API HTTP. Start inicia
+// todos los componentes en orden de dependencias y, si crypto.http.addr está
+// configurado, el servidor HTTP; Stop los detiene en orden inverso.
+type App interface {
+	lifecycle.Component
+	// Handler devuelve el router HTTP, para usarlo sin iniciar el servidor
+	Handler() http.Handler
+}
+
+type app struct {
+	cfg        *config.Config
+	logger     *zap.Logger
+	options    options
+	components *lifecycle.Manager
+	router     *gin.Engine
+	db         *gorm.DB
+	publisher  events.Publisher
+	// natsConn conexión de publicación de eventos, si está configurada
+	natsConn *nats.Conn
+}
+
+// NewApp crea la aplicación a partir de la configuración. Las dependencias no
+// reemplazadas con opciones se crean según la configuración; la conexión a la
+// DB sólo se abre si algún store la requiere.
+func NewApp(cfg *config.Config, logger *zap.Logger, opts ...Option) (App, error) {
+	a := &app{
+		cfg:    cfg,
+		logger: logger,
+		options: options{
+			providers:  make(map[string]ProviderFactory),
+			now:        time.Now,
+			registerer: prometheus.DefaultRegisterer,
+		},
+		components: lifecycle.NewManager(logger),
+		router:     gin.New(),
+	}
+
+	for _, opt := range opts {
+		opt(&a.options)
+	}
+
+	if err := a.build(); err != nil {
+		if a.db != nil && a.options.db == nil {
+			closeGormDBConnection(a.db)
+		}
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *app) Start(ctx context.Context) error {
+	return a.components.Start(ctx)
+}
+
+func (a *app) Stop(ctx context.Context) error {
+	return a.components.Stop(ctx)
+}
+
+func (a *app) Handler() http.Handler {
+	return a.router
+}
+
+// build crea y conecta todos los componentes
+func (a *app) build() error {
+	cfg, logger, r := a.cfg, a.logger, a.router
+	clock := service.WithClock(a.options.now)
+
+	// Configuración para métricas (Prometheus)
+	p := ginprom.NewPrometheus("gin")
+	p.ReqCntURLLabelMappingFn = func(c *gin.Context) string {
+		return c.Request.URL.Path
+	}
+	p.Use(r)
+
+	// Logging
+	if cfg.GetBool("crypto.debug.mode") {
+		r.Use(ginzap.Ginzap(logger, time.RFC3339Nano, true))
+	}
+	r.Use(ginzap.RecoveryWithZap(logger, true))
+
+	if cfg.GetBool("crypto.election.enabled") && !cfg.GetBool("crypto.snapshot.enabled") {
+		return errors.New("crypto.election.enabled requires crypto.snapshot.enabled")
+	}
+
+	// Registro de salud de componentes
+	healthRegistry := health.NewRegistry(cfg.GetDuration("crypto.health.check.timeout"))
+
+	// Conexión a DB, si algún store la requiere
+	if err := a.openDB(healthRegistry); err != nil {
+		return err
+	}
+
+	// Cache de billeteras
+	defaultExpiration := cfg.GetDuration("crypto.cache.default.expiration")
+	cleanupInterval := cfg.GetDuration("crypto.cache.cleanup.interval")
+	walletCache := cache.New(defaultExpiration, cleanupInterval)
+
+	// Stores
+	marketDataStore := a.options.marketDataStore
+	if marketDataStore == nil {
+		marketDataStore = memory.NewMarketDataStore()
+	}
+	walletStore := a.options.walletStore
+	if walletStore == nil {
+		walletStore = db.NewWalletStore(a.db)
+	}
+
+	if cfg.GetBool("crypto.cache.enabled") {
+		logger.Info("wallet cache is enabled")
+		walletStore = cacheStore.NewWalletCacheStore(walletCache, walletStore)
+		healthRegistry.AddCheck("cache", createCacheCheck(logger, walletCache), false)
+	} else {
+		logger.Info("wallet cache is disabled")
+	}
+
+	// Snapshot de market data: se restaura antes de empezar a consumir
+	observers := []service.MarketDataObserver{}
+	snapshotStore := a.options.snapshotStore
+	var snapshotService service.SnapshotService
+	if cfg.GetBool("crypto.snapshot.enabled") {
+		if snapshotStore == nil {
+			snapshotStore = db.NewMarketDataSnapshotStore(a.db)
+		}
+		snapshotService = service.NewSnapshotService(logger, snapshotStore, marketDataStore,
+			cfg.GetDuration("crypto.snapshot.interval"))
+
+		if err := snapshotService.Restore(); err != nil {
+			logger.Error("error restoring market data snapshot", zap.Error(err))
+		}
+		a.components.Add("snapshot", snapshotService, "db")
+		observers = append(observers, snapshotService)
+	}
+
+	// Monitoreo de frescura por símbolo
+	freshnessService := service.NewFreshnessService(logger, a.options.registerer,
+		createFreshnessNotifier(cfg),
+		cfg.GetDuration("crypto.freshness.stale.threshold"),
+		cfg.GetDuration("crypto.freshness.check.interval"),
+		cfg.GetDuration("crypto.freshness.webhook.timeout"),
+		clock)
+	observers = append(observers, freshnessService)
+
+	// Publicación de eventos para otros sistemas
+	if err := a.createPublisher(healthRegistry); err != nil {
+		return err
+	}
+	observers = append(observers, a.publisher)
+
+	// Market Data channel: el buffer evita que un store lento bloquee a los proveedores
+	mdChannel := make(model.MdChannel, cfg.GetInt("crypto.marketdata.queue.size"))
+
+	// Services
+	derivedInstruments, err := createDerivedInstruments(cfg)
+	if err != nil {
+		return err
+	}
+	indices, err := loadIndices(cfg)
+	if err != nil {
+		return err
+	}
+	indexService := service.NewIndexService(logger, indices, mdChannel,
+		cfg.GetInt("crypto.indices.history.size"), cfg.GetInt("crypto.indices.queue.size"))
+	subscriberPolicy, err := hub.ParsePolicy(cfg.GetString("crypto.marketdata.subscriber.policy"))
+	if err != nil {
+		return err
+	}
+	marketDataHub := hub.NewHub(cfg.GetInt("crypto.marketdata.subscriber.buffer"), subscriberPolicy, a.options.registerer)
+	marketDataService := service.NewMarketDataService(logger, marketDataStore,
+		service.WithHub(marketDataHub),
+		service.WithDerivedInstruments(derivedInstruments),
+		service.WithObservers(append(observers, indexService)...))
+	// Demanda de símbolos según las valuaciones de wallets, para priorizar su consulta
+	symbolDemand := service.NewSymbolDemand(cfg.GetDuration("crypto.demand.window"),
+		cfg.GetInt("crypto.demand.hot.threshold"), clock)
+	walletService := service.NewWalletService(walletStore, marketDataService, symbolDemand)
+	if err := a.addPositionConsumer(walletService, healthRegistry); err != nil {
+		return err
+	}
+	if err := a.addOutboxRelay(); err != nil {
+		return err
+	}
+
+	// Consumo de MD: los observers se inician antes y se detienen después del
+	// consumidor. Al detenerlo se cierra el channel y se procesa lo pendiente.
+	marketDataDeps := []string{"freshness", "events"}
+	if snapshotService != nil {
+		marketDataDeps = append(marketDataDeps, "snapshot")
+	}
+	a.components.Add("freshness", freshnessService)
+	a.components.Add("marketdata", lifecycle.Hook{
+		OnStart: func(ctx context.Context) error {
+			marketDataService.ConsumeMD(mdChannel)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(mdChannel)
+			return marketDataService.Drain(ctx)
+		},
+	}, marketDataDeps...)
+	a.components.Add("index", indexService, "marketdata")
+	// Valuación de wallets en vivo (/wallet/live)
+	walletWatcher := service.NewWalletWatcher(logger, walletStore, walletService, marketDataService,
+		cfg.GetDuration("crypto.wallet.live.min.interval"), cfg.GetDuration("crypto.wallet.live.refresh.interval"))
+	a.components.Add("walletwatcher", walletWatcher, "marketdata")
+
+	// Proveedores de market data, combinados por el agregador
+	mdAggregator := aggregator.NewAggregator(cfg, logger, mdChannel)
+	providers, err := a.createProviders(mdAggregator, healthRegistry, freshnessService, symbolDemand)
+	if err != nil {
+		return err
+	}
+	// Suscripciones administrables en runtime, persistidas en DB
+	subscribers := map[string]crypto.Subscriber{}
+	for name, provider := range providers {
+		if subscriber, ok := provider.(crypto.Subscriber); ok {
+			subscribers[name] = subscriber
+		}
+	}
+	subscriptionStore := a.options.subscriptionStore
+	if subscriptionStore == nil {
+		subscriptionStore = db.NewSubscriptionStore(a.db)
+	}
+	subscriptionService := service.NewSubscriptionService(logger, subscriptionStore, subscribers, clock)
+	if err := subscriptionService.Restore(); err != nil {
+		logger.Error("error restoring subscriptions", zap.Error(err))
+	}
+
+	providerSymbols := func() []string {
+		symbols := []string{}
+		for _, provider := range providers {
+			symbols = append(symbols, provider.Symbols()...)
+		}
+		return symbols
+	}
+	freshnessService.Track(providerSymbols()...)
+	if err := applyPairsFileOptions(cfg, logger, freshnessService); err != nil {
+		return err
+	}
+	healthRegistry.AddCheck("marketdata", service.NewMarketDataCheck(
+		marketDataStore, providerSymbols, cfg.GetDuration("crypto.health.max.age"), clock), true)
+
+	if err := a.addProviders(mdAggregator, providers, snapshotStore, mdChannel); err != nil {
+		return err
+	}
+
+	// Controllers
+	walletController := controller.NewWalletController(logger, walletService, walletWatcher,
+		cfg.GetStringSlice("crypto.wallet.live.allowed.origins"))
+	indexController := controller.NewIndexController(logger, indexService)
+	healthController := controller.NewHealthController(healthRegistry)
+	marketDataController := controller.NewMarketDataController(logger, freshnessService,
+		marketDataService, cfg.GetDuration("crypto.sse.heartbeat.interval"))
+	subscriptionController := controller.NewSubscriptionController(logger, subscriptionService)
+
+	// Controller routes
+	r.GET("/wallet/value", walletController.GetWalletValue)
+	r.GET("/wallet/live", walletController.GetWalletValueLive)
+	r.GET("/index/:symbol", indexController.GetIndex)
+	r.GET("/index/:symbol/history", indexController.GetIndexHistory)
+	r.GET("/marketdata", marketDataController.GetMarketData)
+	r.GET("/marketdata/status", marketDataController.GetStatus)
+	r.GET("/marketdata/stream", marketDataController.Stream)
+	r.GET("/marketdata/:symbol", marketDataController.GetMarketDataBySymbol)
+
+	// Administración
+	adminToken := cfg.GetString("crypto.admin.token")
+	if adminToken == "" {
+		logger.Warn("crypto.admin.token is not configured, admin endpoints are disabled")
+	}
+	admin := r.Group("/admin", controller.AdminAuth(adminToken))
+	admin.GET("/subscriptions/:provider", subscriptionController.GetSubscriptions)
+	admin.POST("/subscriptions/:provider", subscriptionController.AddSubscription)
+	admin.DELETE("/subscriptions/:provider/:symbol", subscriptionController.RemoveSubscription)
+	admin.POST("/subscriptions/:provider/:symbol/pause", subscriptionController.PauseSubscription)
+	admin.POST("/subscriptions/:provider/:symbol/resume", subscriptionController.ResumeSubscription)
+
+	// Health check handlers
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "")
+	})
+	r.GET("/livez", healthController.Livez)
+	r.GET("/readyz", healthController.Readyz)
+	r.GET("/health", healthController.GetHealth)
+
+	a.addHTTPServer()
+
+	return nil
+}
+
+// addProviders registra el agregador y los proveedores. Con elección de líder
+// sólo se inician en la réplica líder; el resto publica los precios que el líder
+// persiste en el snapshot.
+func (a *app) addProviders(
+	mdAggregator aggregator.Aggregator,
+	providers map[string]crypto.Client,
+	snapshotStore store.MarketDataSnapshotStore,
+	mdChannel model.MdChannel,
+) error {
+	if !a.cfg.GetBool("crypto.election.enabled") {
+		a.components.Add("aggregator", mdAggregator, "marketdata")
+		for name, provider := range providers {
+			a.components.Add("provider."+name, provider, "aggregator")
+		}
+		return nil
+	}
+
+	leader := lifecycle.NewManager(a.logger)
+	leader.Add("aggregator", mdAggregator)
+	for name, provider := range providers {
+		leader.Add("provider."+name, provider, "aggregator")
+	}
+
+	lock := a.options.lock
+	if lock == nil {
+		sqlDB, err := a.db.DB()
+		if err != nil {
+			return err
+		}
+		lock = election.NewPostgresLock(sqlDB, a.cfg.GetInt64("crypto.election.key"))
+	}
+
+	elector := election.NewElector(a.logger, lock, leader,
+		a.cfg.GetDuration("crypto.election.interval"), a.cfg.GetDuration("crypto.shutdown.timeout"))
+	follower := service.NewFollowerService(a.logger, snapshotStore, mdChannel,
+		a.cfg.GetDuration("crypto.election.sync.interval"), elector.IsLeader)
+
+	a.components.Add("election", elector, "db", "marketdata")
+	a.components.Add("follower", follower, "marketdata")
+
+	return nil
+}
+
+// createPublisher crea el publicador de eventos en NATS, si está configurado. La
+// conexión se cierra luego de detener el consumo de market data.
+func (a *app) createPublisher(healthRegistry *health.Registry) error {
+	a.publisher = a.options.publisher
+	url := a.cfg.GetString("crypto.events.nats.url")
+
+	if a.publisher != nil || url == "" {
+		if a.publisher == nil {
+			a.publisher = events.NopPublisher{}
+		}
+		a.components.Add("events", lifecycle.Hook{})
+		return nil
+	}
+
+	conn, err := nats.Connect(url,
+		nats.Name(pkg.ServiceName),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1))
+	if err != nil {
+		return fmt.Errorf("connecting to NATS: %w", err)
+	}
+
+	a.natsConn = conn
+	a.publisher = events.NewNATSPublisher(a.logger, conn, a.cfg.GetString("crypto.events.nats.subject.prefix"))
+	a.components.Add("events", lifecycle.Hook{
+		OnStop: func(ctx context.Context) error {
+			defer conn.Close()
+			if _, ok := ctx.Deadline(); !ok {
+				return conn.Flush()
+			}
+			return conn.FlushWithContext(ctx)
+		},
+	})
+	healthRegistry.AddCheck("nats", func(ctx context.Context) error {
+		if status := conn.Status(); status != nats.CONNECTED {
+			return fmt.Errorf("NATS connection status %d", status)
+		}
+		return nil
+	}, false)
+
+	return nil
+}
+
+// addPositionConsumer registra el consumo de cambios de posición, si está
+// configurado. Se detiene antes que la DB y que la publicación de eventos.
+func (a *app) addPositionConsumer(walletService service.WalletService, healthRegistry *health.Registry) error {
+	url := a.cfg.GetString("crypto.positions.nats.url")
+	if url == "" {
+		return nil
+	}
+
+	conn, err := nats.Connect(url,
+		nats.Name(pkg.ServiceName),
+		nats.MaxReconnects(-1))
+	if err != nil {
+		return fmt.Errorf("connecting to NATS: %w", err)
+	}
+
+	positionConsumer := consumer.NewPositionConsumer(a.cfg, a.logger, conn, walletService)
+	a.components.Add("positions", lifecycle.Hook{
+		OnStart: positionConsumer.Start,
+		OnStop: func(ctx context.Context) error {
+			defer conn.Close()
+			return positionConsumer.Stop(ctx)
+		},
+	}, "db", "events")
+
+	// Los mensajes aplicados se eliminan pasada la retención
+	if a.db != nil {
+		pruner := consumer.NewProcessedMessagesPruner(a.logger, db.NewProcessedMessageStore(a.db),
+			a.cfg.GetDuration("crypto.positions.processed.retention"),
+			a.cfg.GetDuration("crypto.positions.processed.prune.interval"))
+		a.components.Add("positions.prune", pruner, "db")
+	}
+
+	healthRegistry.AddCheck("positions", func(ctx context.Context) error {
+		if status := conn.Status(); status != nats.CONNECTED {
+			return fmt.Errorf("NATS connection status %d", status)
+		}
+		return nil
+	}, false)
+
+	return nil
+}
+
+// addOutboxRelay registra la entrega de los eventos de wallets del outbox al
+// sink configurado. Se detiene antes que la DB y que la conexión a NATS.
+func (a *app) addOutboxRelay() error {
+	var sink outbox.Sink
+	switch name := a.cfg.GetString("crypto.outbox.sink"); name {
+	case "":
+		return nil
+	case "log":
+		sink = outbox.NewLogSink(a.logger)
+	case "webhook":
+		url := a.cfg.GetString("crypto.outbox.webhook.url")
+		if url == "" {
+			return errors.New("crypto.outbox.sink webhook requires crypto.outbox.webhook.url")
+		}
+		httpClient := &http.Client{Timeout: a.cfg.GetDuration("crypto.outbox.webhook.timeout")}
+		sink = outbox.NewWebhookSink(notifier.NewWebhookNotifier(httpClient, url))
+	case "nats":
+		if a.natsConn == nil {
+			return errors.New("crypto.outbox.sink nats requires crypto.events.nats.url")
+		}
+		sink = outbox.NewNATSSink(a.natsConn, a.cfg.GetString("crypto.events.nats.subject.prefix"))
+	default:
+		return fmt.Errorf("unknown outbox sink %s", name)
+	}
+
+	outboxStore := a.options.outboxStore
+	if outboxStore == nil {
+		outboxStore = db.NewOutboxStore(a.db)
+	}
+	relay := outbox.NewRelay(a.logger, outboxStore, sink,
+		a.cfg.GetDuration("crypto.outbox.interval"), a.cfg.GetInt("crypto.outbox.batch.size"),
+		a.cfg.GetInt("crypto.outbox.max.attempts"))
+	a.components.Add("outbox", relay, "db", "events")
+
+	return nil
+}
+
+// openDB abre la conexión a la DB si no se indicó una y algún store la
+// requiere. La conexión abierta por la aplicación se cierra al final.
+func (a *app) openDB(healthRegistry *health.Registry) error {
+	a.db = a.options.db
+
+	if a.db == nil && a.requiresDB() {
+		gormDB, err := createGormDB(a.cfg)
+		if err != nil {
+			return err
+		}
+		a.db = gormDB
+
+		a.components.Add("db", lifecycle.Hook{
+			OnStop: func(ctx context.Context) error {
+				closeGormDBConnection(gormDB)
+				return nil
+			},
+		})
+	} else {
+		a.components.Add("db", lifecycle.Hook{})
+	}
+
+	if a.db != nil {
+		gormDB := a.db
+		healthRegistry.AddCheck("db", func(ctx context.Context) error {
+			sqlDB, err := gormDB.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}, true)
+	}
+
+	return nil
+}
+
+func (a *app) requiresDB() bool {
+	return a.options.walletStore == nil ||
+		a.options.subscriptionStore == nil ||
+		(a.cfg.GetString("crypto.outbox.sink") != "" && a.options.outboxStore == nil) ||
+		(a.cfg.GetBool("crypto.snapshot.enabled") && a.options.snapshotStore == nil) ||
+		(a.cfg.GetBool("crypto.election.enabled") && a.options.lock == nil)
+}
+
+// addHTTPServer registra el servidor HTTP, si hay una dirección configurada.
+// Se inicia luego de todos los componentes y se detiene antes.
+func (a *app) addHTTPServer() {
+	addr := a.cfg.GetString("crypto.http.addr")
+	if addr == "" {
+		return
+	}
+
+	// Al iniciar el apagado se cancela el contexto de los requests, para terminar
+	// las conexiones de larga duración (streams)
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     a.router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+	srv.RegisterOnShutdown(cancelRequests)
+
+	httpShutdownTimeout := a.cfg.GetDuration("crypto.http.shutdown.timeout")
+	a.components.Add("http", lifecycle.Hook{
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("listening on %s: %w", addr, err)
+			}
+
+			go func() {
+				a.logger.Info("starting HTTP server", zap.String("addr", addr))
+
+				err := srv.Serve(listener)
+				if err == http.ErrServerClosed {
+					a.logger.Info("shutting down server", zap.Error(err))
+				} else {
+					a.logger.Error("shutting down server", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, httpShutdownTimeout)
+			defer cancel()
+			return srv.Shutdown(ctx)
+		},
+	}, "db", "marketdata", "index")
+}
+
+// createCacheCheck informa el estado de la cache sin escribir en ella: la cache
+// es local al proceso, así que basta con poder consultarla
+func createCacheCheck(logger *zap.Logger, walletCache *cache.Cache) health.Check {
+	return func(ctx context.Context) error {
+		logger.Debug("wallet cache status", zap.Int("items", walletCache.ItemCount()))
+		return nil
+	}
+}
@@ -0,0 +1,334 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/election"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeProvider publica una vez los precios indicados al iniciar
+type fakeProvider struct {
+	mdChannel model.MdChannel
+	mds       []model.MarketData
+	routines  lifecycle.Group
+	started   int32
+}
+
+func (p *fakeProvider) Start(ctx context.Context) error {
+	atomic.AddInt32(&p.started, 1)
+	p.routines.Go(func(ctx context.Context) {
+		for _, md := range p.mds {
+			select {
+			case p.mdChannel <- md:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func (p *fakeProvider) Stop(ctx context.Context) error {
+	return p.routines.Stop(ctx)
+}
+
+func (p *fakeProvider) Symbols() []string {
+	symbols := []string{}
+	for _, md := range p.mds {
+		symbols = append(symbols, md.Symbol)
+	}
+	return symbols
+}
+
+// fakeSnapshotStore snapshot en memoria compartido por varias réplicas
+type fakeSnapshotStore struct {
+	mu  sync.Mutex
+	mds map[string]model.MarketData
+}
+
+func (s *fakeSnapshotStore) SaveMD(mds []model.MarketData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, md := range mds {
+		if md.LastPriceDateTime.After(s.mds[md.Symbol].LastPriceDateTime) {
+			s.mds[md.Symbol] = md
+		}
+	}
+	return nil
+}
+
+func (s *fakeSnapshotStore) LoadMD() ([]model.MarketData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs := []model.MarketData{}
+	for _, md := range s.mds {
+		rs = append(rs, md)
+	}
+	return rs, nil
+}
+
+func get(handler http.Handler, url string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", url, nil)
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestAppInProcess(t *testing.T) {
+	t.Setenv("MTZ_CRYPTO_PROVIDERS", "fake")
+	t.Setenv("MTZ_CRYPTO_HEALTH_MAX_AGE", "1m")
+	t.Setenv("MTZ_CRYPTO_HEALTH_CHECK_TIMEOUT", "1s")
+	t.Setenv("MTZ_CRYPTO_FRESHNESS_CHECK_INTERVAL", "1m")
+	t.Setenv("MTZ_CRYPTO_CACHE_ENABLED", "true")
+	cfg := config.NewConfig(&flag.FlagSet{})
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+
+	walletStoreMock := new(mocks.WalletStore)
+	walletStoreMock.On("GetWallet", "wallet1").Return(model.Wallet{
+		ID:    "wallet1",
+		Items: []model.WalletItem{{Symbol: "BTCUSD", Quantity: decimal.NewFromInt(2)}},
+	}, nil)
+
+	application, err := NewApp(cfg, zap.NewNop(),
+		WithWalletStore(walletStoreMock),
+		WithSubscriptionStore(new(mocks.SubscriptionStore)),
+		WithRegisterer(prometheus.NewRegistry()),
+		// El precio sólo está vigente según el reloj de la app
+		WithClock(func() time.Time { return now.Add(30 * time.Second) }),
+		WithProvider("fake", func(mdChannel model.MdChannel, reporter crypto.Reporter) (crypto.Client, error) {
+			return &fakeProvider{mdChannel: mdChannel, mds: []model.MarketData{{
+				Symbol:            "BTCUSD",
+				LastPrice:         decimal.RequireFromString("45000.5"),
+				LastPriceDateTime: now,
+			}}}, nil
+		}),
+	)
+	require.NoError(t, err)
+	handler := application.Handler()
+
+	// Antes de iniciar no hay precios
+	assert.Equal(t, http.StatusServiceUnavailable, get(handler, "/readyz").Code)
+
+	require.NoError(t, application.Start(context.Background()))
+
+	assert.Eventually(t, func() bool {
+		return get(handler, "/wallet/value?wallet=wallet1").Code == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	w := get(handler, "/wallet/value?wallet=wallet1")
+	assert.JSONEq(t, `{"walletId":"wallet1","value":"90001","dateTime":"2021-08-10T15:00:00Z"}`, w.Body.String())
+	assert.Equal(t, http.StatusOK, get(handler, "/livez").Code)
+	assert.Equal(t, http.StatusOK, get(handler, "/readyz").Code)
+
+	// La cache informa su estado sin ser crítica
+	w = get(handler, "/health")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `{"name":"cache","status":"up","critical":false`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, application.Stop(ctx))
+	walletStoreMock.AssertExpectations(t)
+}
+
+func TestAppUnknownProvider(t *testing.T) {
+	t.Setenv("MTZ_CRYPTO_PROVIDERS", "unknown")
+	cfg := config.NewConfig(&flag.FlagSet{})
+
+	_, err := NewApp(cfg, zap.NewNop(),
+		WithWalletStore(new(mocks.WalletStore)),
+		WithSubscriptionStore(new(mocks.SubscriptionStore)),
+		WithRegisterer(prometheus.NewRegistry()),
+	)
+	assert.EqualError(t, err, "unknown market data provider unknown")
+}
+
+func TestAppLeaderElection(t *testing.T) {
+	t.Setenv("MTZ_CRYPTO_PROVIDERS", "fake")
+	t.Setenv("MTZ_CRYPTO_FRESHNESS_CHECK_INTERVAL", "1m")
+	t.Setenv("MTZ_CRYPTO_SNAPSHOT_ENABLED", "true")
+	t.Setenv("MTZ_CRYPTO_ELECTION_ENABLED", "true")
+	t.Setenv("MTZ_CRYPTO_ELECTION_INTERVAL", "5ms")
+	t.Setenv("MTZ_CRYPTO_ELECTION_SYNC_INTERVAL", "5ms")
+	t.Setenv("MTZ_CRYPTO_SHUTDOWN_TIMEOUT", "1s")
+	cfg := config.NewConfig(&flag.FlagSet{})
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	mutex := &election.MemoryMutex{}
+	snapshotStore := &fakeSnapshotStore{mds: map[string]model.MarketData{}}
+
+	// Cada réplica tiene su proveedor, que publica un precio distinto
+	replica := func(price string, ts time.Time) (App, *fakeProvider) {
+		provider := &fakeProvider{mds: []model.MarketData{{
+			Symbol:            "BTCUSD",
+			LastPrice:         decimal.RequireFromString(price),
+			LastPriceDateTime: ts,
+		}}}
+
+		walletStoreMock := new(mocks.WalletStore)
+		walletStoreMock.On("GetWallet", "wallet1").Return(model.Wallet{
+			ID:    "wallet1",
+			Items: []model.WalletItem{{Symbol: "BTCUSD", Quantity: decimal.NewFromInt(1)}},
+		}, nil)
+
+		application, err := NewApp(cfg, zap.NewNop(),
+			WithWalletStore(walletStoreMock),
+			WithSubscriptionStore(new(mocks.SubscriptionStore)),
+			WithSnapshotStore(snapshotStore),
+			WithLock(election.NewMemoryLock(mutex)),
+			WithRegisterer(prometheus.NewRegistry()),
+			WithProvider("fake", func(mdChannel model.MdChannel, reporter crypto.Reporter) (crypto.Client, error) {
+				provider.mdChannel = mdChannel
+				return provider, nil
+			}),
+		)
+		require.NoError(t, err)
+		return application, provider
+	}
+
+	value := func(application App) string {
+		w := get(application.Handler(), "/wallet/value?wallet=wallet1")
+		if w.Code != http.StatusOK {
+			return ""
+		}
+		return w.Body.String()
+	}
+
+	leader, leaderProvider := replica("100", now)
+	follower, followerProvider := replica("200", now.Add(time.Minute))
+
+	require.NoError(t, leader.Start(context.Background()))
+	assert.Eventually(t, func() bool { return value(leader) != "" }, time.Second, time.Millisecond)
+	require.NoError(t, follower.Start(context.Background()))
+
+	// El seguidor no consulta a su proveedor: publica el precio del líder
+	assert.Eventually(t, func() bool { return value(follower) != "" }, time.Second, time.Millisecond)
+	assert.JSONEq(t, `{"walletId":"wallet1","value":"100","dateTime":"2021-08-10T15:00:00Z"}`, value(follower))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&leaderProvider.started))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&followerProvider.started))
+
+	// Al detenerse el líder, el seguidor toma el liderazgo e inicia su proveedor
+	require.NoError(t, leader.Stop(context.Background()))
+	assert.Eventually(t, func() bool {
+		return value(follower) == `{"walletId":"wallet1","value":"200","dateTime":"2021-08-10T15:01:00Z"}`
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&followerProvider.started))
+
+	require.NoError(t, follower.Stop(context.Background()))
+}
+
+func TestAppPublishesMarketDataToNATS(t *testing.T) {
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1})
+	require.NoError(t, err)
+	go ns.Start()
+	require.True(t, ns.ReadyForConnections(5*time.Second))
+	defer ns.Shutdown()
+
+	conn, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+	sub, err := conn.SubscribeSync("md.>")
+	require.NoError(t, err)
+
+	t.Setenv("MTZ_CRYPTO_PROVIDERS", "fake")
+	t.Setenv("MTZ_CRYPTO_FRESHNESS_CHECK_INTERVAL", "1m")
+	t.Setenv("MTZ_CRYPTO_EVENTS_NATS_URL", ns.ClientURL())
+	cfg := config.NewConfig(&flag.FlagSet{})
+
+	application, err := NewApp(cfg, zap.NewNop(),
+		WithWalletStore(new(mocks.WalletStore)),
+		WithSubscriptionStore(new(mocks.SubscriptionStore)),
+		WithRegisterer(prometheus.NewRegistry()),
+		WithProvider("fake", func(mdChannel model.MdChannel, reporter crypto.Reporter) (crypto.Client, error) {
+			return &fakeProvider{mdChannel: mdChannel, mds: []model.MarketData{{
+				Symbol:            "ETHUSD",
+				LastPrice:         decimal.RequireFromString("3000"),
+				LastPriceDateTime: time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC),
+			}}}, nil
+		}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, application.Start(context.Background()))
+
+	msg, err := sub.NextMsg(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "md.ETHUSD", msg.Subject)
+	assert.JSONEq(t, `{"version":1,"symbol":"ETHUSD","price":"3000",
+		"timestamp":"2021-08-10T15:00:00Z","sources":["fake"]}`, string(msg.Data))
+
+	assert.NoError(t, application.Stop(context.Background()))
+}
+
+func TestAppDeliversOutboxToNATS(t *testing.T) {
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1})
+	require.NoError(t, err)
+	go ns.Start()
+	require.True(t, ns.ReadyForConnections(5*time.Second))
+	defer ns.Shutdown()
+
+	conn, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+	sub, err := conn.SubscribeSync("wallet.>")
+	require.NoError(t, err)
+
+	t.Setenv("MTZ_CRYPTO_FRESHNESS_CHECK_INTERVAL", "1m")
+	t.Setenv("MTZ_CRYPTO_OUTBOX_SINK", "nats")
+	t.Setenv("MTZ_CRYPTO_OUTBOX_INTERVAL", "1m")
+	t.Setenv("MTZ_CRYPTO_OUTBOX_BATCH_SIZE", "10")
+	cfg := config.NewConfig(&flag.FlagSet{})
+
+	outboxStore := new(mocks.OutboxStore)
+	outboxStore.On("ClaimPending", 10, mock.Anything).Return(
+		[]model.OutboxEvent{{ID: 1, WalletID: "wallet1", Payload: []byte(`{}`)}}, nil).Once()
+	outboxStore.On("ClaimPending", 10, mock.Anything).Return(nil, nil)
+	outboxStore.On("Delete", int64(1)).Return(nil)
+	opts := []Option{
+		WithWalletStore(new(mocks.WalletStore)),
+		WithSubscriptionStore(new(mocks.SubscriptionStore)),
+		WithOutboxStore(outboxStore),
+	}
+
+	// El sink nats usa la conexión de publicación de eventos
+	_, err = NewApp(cfg, zap.NewNop(), append(opts, WithRegisterer(prometheus.NewRegistry()))...)
+	assert.EqualError(t, err, "crypto.outbox.sink nats requires crypto.events.nats.url")
+
+	t.Setenv("MTZ_CRYPTO_EVENTS_NATS_URL", ns.ClientURL())
+	cfg = config.NewConfig(&flag.FlagSet{})
+	application, err := NewApp(cfg, zap.NewNop(), append(opts, WithRegisterer(prometheus.NewRegistry()))...)
+	require.NoError(t, err)
+	require.NoError(t, application.Start(context.Background()))
+
+	msg, err := sub.NextMsg(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "wallet.wallet1", msg.Subject)
+	assert.Equal(t, "{}", string(msg.Data))
+
+	assert.NoError(t, application.Stop(context.Background()))
+}
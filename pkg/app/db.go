@@ -0,0 +1,70 @@
+package app
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// createGormDB configuración de acceso a datos y GORM
+func createGormDB(cfg *config.Config) (*gorm.DB, error) {
+	connStr := fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s %s",
+		cfg.GetString("crypto.postgres.host"),
+		cfg.GetInt("crypto.postgres.port"),
+		cfg.GetString("crypto.postgres.username"),
+		cfg.GetString("crypto.postgres.dbname"),
+		cfg.GetString("crypto.postgres.password"),
+		cfg.GetString("crypto.postgres.extraopts"),
+	)
+
+	dbPool := &sql.DB{}
+	dbPool.SetMaxIdleConns(cfg.GetInt("crypto.postgres.maxidleconns"))
+
+	gormConfig := &gorm.Config{
+		Logger:      logger.Discard,
+		PrepareStmt: true,
+		ConnPool:    dbPool,
+	}
+
+	if cfg.GetBool("crypto.postgres.sqldebug") {
+		newLogger := logger.New(
+			log.New(os.Stderr, "crypto", 0), // io writer
+			logger.Config{
+				SlowThreshold: time.Second, // Slow SQL threshold
+				LogLevel:      logger.Info, // Log level
+				Colorful:      false,       // Disable color
+			},
+		)
+		gormConfig.Logger = newLogger
+	}
+
+	db, err := gorm.Open(postgres.Open(connStr), gormConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to connect to DB: %w", err)
+	}
+
+	return db, nil
+}
+
+// closeGormDBConnection cierra conexiones a DB relacional
+func closeGormDBConnection(db *gorm.DB) {
+	stmtManger, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+
+	if ok {
+		for _, stmt := range stmtManger.Stmts {
+			stmt.Close() // close the prepared statement
+		}
+	}
+
+	dbLocal, err := db.DB()
+	if err == nil {
+		dbLocal.Close() //CloseDB
+	}
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/election"
+	"github.com/matbarofex/mtz-crypto/pkg/events"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// ProviderFactory crea el cliente de un proveedor de market data que publica en
+// mdChannel e informa su estado a reporter
+type ProviderFactory func(mdChannel model.MdChannel, reporter crypto.Reporter) (crypto.Client, error)
+
+// Option reemplaza una dependencia de la aplicación
+type Option func(o *options)
+
+type options struct {
+	db                *gorm.DB
+	walletStore       store.WalletStore
+	marketDataStore   store.MarketDataStore
+	snapshotStore     store.MarketDataSnapshotStore
+	subscriptionStore store.SubscriptionStore
+	outboxStore       store.OutboxStore
+	providers         map[string]ProviderFactory
+	lock              election.Lock
+	publisher         events.Publisher
+	now               func() time.Time
+	registerer        prometheus.Registerer
+}
+
+// WithDB usa una conexión existente en lugar de conectarse a PostgreSQL. La
+// conexión no se cierra al detener la aplicación.
+func WithDB(db *gorm.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// WithWalletStore reemplaza el store de wallets en DB. La cache se sigue
+// aplicando según la configuración.
+func WithWalletStore(walletStore store.WalletStore) Option {
+	return func(o *options) {
+		o.walletStore = walletStore
+	}
+}
+
+// WithMarketDataStore reemplaza el store de market data en memoria
+func WithMarketDataStore(marketDataStore store.MarketDataStore) Option {
+	return func(o *options) {
+		o.marketDataStore = marketDataStore
+	}
+}
+
+// WithSnapshotStore reemplaza el store de snapshots de market data en DB
+func WithSnapshotStore(snapshotStore store.MarketDataSnapshotStore) Option {
+	return func(o *options) {
+		o.snapshotStore = snapshotStore
+	}
+}
+
+// WithSubscriptionStore reemplaza el store de suscripciones en DB
+func WithSubscriptionStore(subscriptionStore store.SubscriptionStore) Option {
+	return func(o *options) {
+		o.subscriptionStore = subscriptionStore
+	}
+}
+
+// WithOutboxStore reemplaza el outbox en DB del que se entregan los eventos de
+// wallets
+func WithOutboxStore(outboxStore store.OutboxStore) Option {
+	return func(o *options) {
+		o.outboxStore = outboxStore
+	}
+}
+
+// WithProvider reemplaza o agrega el proveedor indicado. Sólo se crea si está
+// habilitado en crypto.providers.
+func WithProvider(name string, factory ProviderFactory) Option {
+	return func(o *options) {
+		o.providers[name] = factory
+	}
+}
+
+// WithLock reemplaza el advisory lock de PostgreSQL con el que se elige la
+// réplica líder
+func WithLock(lock election.Lock) Option {
+	return func(o *options) {
+		o.lock = lock
+	}
+}
+
+// WithPublisher reemplaza el publicador de eventos en NATS
+func WithPublisher(publisher events.Publisher) Option {
+	return func(o *options) {
+		o.publisher = publisher
+	}
+}
+
+// WithClock reemplaza la fuente de la hora actual de los servicios
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithRegisterer registra las métricas de los servicios en registerer en lugar
+// del registry por defecto, por ejemplo para crear varias aplicaciones en un
+// mismo proceso
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.registerer = registerer
+	}
+}
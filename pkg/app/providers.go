@@ -0,0 +1,184 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto/aggregator"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto/binance"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto/cassette"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto/coingecko"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto/cryptonator"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto/replay"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto/simulator"
+	"github.com/matbarofex/mtz-crypto/pkg/health"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/notifier"
+	"github.com/matbarofex/mtz-crypto/pkg/service"
+	"go.uber.org/zap"
+)
+
+// createProviders crea los clientes de los proveedores de market data
+// habilitados. Los reemplazados con WithProvider se crean con su factory.
+func (a *app) createProviders(
+	mdAggregator aggregator.Aggregator,
+	healthRegistry *health.Registry,
+	freshnessService service.FreshnessService,
+	demand crypto.Demand,
+) (map[string]crypto.Client, error) {
+	cfg, logger := a.cfg, a.logger
+	providers := map[string]crypto.Client{}
+
+	for _, name := range cfg.GetStringSlice("crypto.providers") {
+		reporter := crypto.NewMultiReporter(
+			crypto.NewHealthReporter(healthRegistry.Component("provider."+name, false)),
+			freshnessService,
+		)
+
+		var provider crypto.Client
+		var err error
+
+		if factory, found := a.options.providers[name]; found {
+			provider, err = factory(mdAggregator.Channel(name), reporter)
+		} else {
+			switch name {
+			case cryptonator.ProviderName:
+				cryptonatorHTTPClient := &http.Client{Timeout: cfg.GetDuration("crypto.api.cryptonator.timeout")}
+				cryptonatorHTTPClient.Transport, err = createCassetteTransport(cfg, logger, "crypto.api.cryptonator.cassette")
+				if err != nil {
+					break
+				}
+				provider, err = cryptonator.NewCryptonatorClient(
+					cfg, logger, cryptonatorHTTPClient, mdAggregator.Channel(name), reporter, demand)
+			case coingecko.ProviderName:
+				coingeckoHTTPClient := &http.Client{Timeout: cfg.GetDuration("crypto.api.coingecko.timeout")}
+				provider, err = coingecko.NewCoingeckoClient(
+					cfg, logger, coingeckoHTTPClient, mdAggregator.Channel(name), reporter)
+			case binance.ProviderName:
+				dialer := &websocket.Dialer{HandshakeTimeout: cfg.GetDuration("crypto.api.binance.pong.timeout")}
+				provider, err = binance.NewBinanceClient(
+					cfg, logger, dialer, mdAggregator.Channel(name), reporter)
+			case replay.ProviderName:
+				provider = replay.NewReplayClient(cfg, logger, mdAggregator.Channel(name), reporter)
+			case simulator.ProviderName:
+				provider = simulator.NewSimulatorClient(cfg, logger, mdAggregator.Channel(name), reporter)
+			default:
+				return nil, fmt.Errorf("unknown market data provider %s", name)
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("creating market data provider %s: %w", name, err)
+		}
+		providers[name] = provider
+	}
+
+	return providers, nil
+}
+
+// createDerivedInstruments interpreta las definiciones de instrumentos derivados
+func createDerivedInstruments(cfg *config.Config) ([]model.DerivedInstrument, error) {
+	instruments := []model.DerivedInstrument{}
+
+	for _, definition := range cfg.GetStringSlice("crypto.derived.instruments") {
+		instrument, err := service.ParseDerivedInstrument(definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derived instrument: %w", err)
+		}
+		instruments = append(instruments, instrument)
+	}
+
+	return instruments, nil
+}
+
+// loadIndices lee la definición de índices sintéticos, si está configurada
+func loadIndices(cfg *config.Config) ([]model.Index, error) {
+	path := cfg.GetString("crypto.indices.file")
+	if path == "" {
+		return nil, nil
+	}
+
+	indices, err := service.LoadIndices(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading indices from %s: %w", path, err)
+	}
+
+	return indices, nil
+}
+
+// applyPairsFileOptions aplica los umbrales de desactualización por par y
+// advierte sobre pares de proveedores no habilitados
+func applyPairsFileOptions(cfg *config.Config, logger *zap.Logger, freshnessService service.FreshnessService) error {
+	path := cfg.GetString("crypto.pairs.file")
+	if path == "" {
+		return nil
+	}
+
+	pairs, err := crypto.LoadPairsFile(path)
+	if err != nil {
+		return fmt.Errorf("loading pairs file %s: %w", path, err)
+	}
+
+	enabled := map[string]bool{}
+	for _, name := range cfg.GetStringSlice("crypto.providers") {
+		enabled[name] = true
+	}
+
+	for _, pair := range pairs {
+		if !enabled[pair.Provider] {
+			logger.Warn("pair configured for a disabled provider",
+				zap.String("symbol", pair.Symbol),
+				zap.String("provider", pair.Provider))
+			continue
+		}
+
+		if pair.MaxStaleness > 0 {
+			freshnessService.SetStaleThreshold(pair.Symbol, pair.MaxStaleness)
+		}
+	}
+
+	return nil
+}
+
+// createFreshnessNotifier crea el notificador del webhook de operaciones, si está configurado
+func createFreshnessNotifier(cfg *config.Config) notifier.Notifier {
+	url := cfg.GetString("crypto.freshness.webhook.url")
+	if url == "" {
+		return notifier.NopNotifier{}
+	}
+
+	httpClient := &http.Client{Timeout: cfg.GetDuration("crypto.freshness.webhook.timeout")}
+
+	return notifier.NewWebhookNotifier(httpClient, url)
+}
+
+// createCassetteTransport crea el transport HTTP que graba o reproduce el
+// tráfico con la API externa según la configuración indicada
+func createCassetteTransport(cfg *config.Config, logger *zap.Logger, key string) (http.RoundTripper, error) {
+	mode := cfg.GetString(key + ".mode")
+	path := cfg.GetString(key + ".file")
+
+	switch mode {
+	case "":
+		return http.DefaultTransport, nil
+	case cassette.ModeRecord:
+		recorder, err := cassette.NewRecorder(path, http.DefaultTransport)
+		if err != nil {
+			return nil, fmt.Errorf("opening cassette %s: %w", path, err)
+		}
+		logger.Info("recording HTTP traffic", zap.String("file", path))
+		return recorder, nil
+	case cassette.ModeReplay:
+		replayer, err := cassette.NewReplayer(path)
+		if err != nil {
+			return nil, fmt.Errorf("loading cassette %s: %w", path, err)
+		}
+		logger.Info("replaying HTTP traffic", zap.String("file", path))
+		return replayer, nil
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %s", mode)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"strings"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -36,3 +37,22 @@ func NewConfig(flags *flag.FlagSet) *Config {
 
 	return &Config{vp}
 }
+
+// ReadFile lee un archivo de configuración adicional (YAML, JSON, TOML) y lo
+// decodifica en la estructura indicada
+func ReadFile(path string, rawVal interface{}, opts ...viper.DecoderConfigOption) error {
+	vp := viper.New()
+	vp.SetConfigFile(path)
+
+	if err := vp.ReadInConfig(); err != nil {
+		return err
+	}
+
+	return vp.Unmarshal(rawVal, opts...)
+}
+
+// ErrorUnused hace fallar la decodificación si el archivo tiene claves que no
+// corresponden a la estructura, para detectar errores de tipeo
+func ErrorUnused(c *mapstructure.DecoderConfig) {
+	c.ErrorUnused = true
+}
@@ -0,0 +1,200 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/service"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// Headers de los mensajes enviados al dead-letter subject
+const (
+	ErrorHeader   = "Mtz-Error"
+	SubjectHeader = "Mtz-Original-Subject"
+)
+
+const fetchTimeout = 5 * time.Second
+
+// PositionConsumer consume de un stream de JetStream los cambios de posición
+// informados por back-office y los aplica a las wallets
+type PositionConsumer interface {
+	lifecycle.Component
+}
+
+type positionConsumer struct {
+	logger        *zap.Logger
+	conn          *nats.Conn
+	walletService service.WalletService
+	subject       string
+	stream        string
+	durable       string
+	dlqSubject    string
+	dlqStream     string
+	maxDeliver    int
+	js            nats.JetStreamContext
+	routines      lifecycle.Group
+}
+
+func NewPositionConsumer(
+	config *config.Config,
+	logger *zap.Logger,
+	conn *nats.Conn,
+	walletService service.WalletService,
+) PositionConsumer {
+	return &positionConsumer{
+		logger:        logger,
+		conn:          conn,
+		walletService: walletService,
+		subject:       config.GetString("crypto.positions.subject"),
+		stream:        config.GetString("crypto.positions.stream"),
+		durable:       config.GetString("crypto.positions.durable"),
+		dlqSubject:    config.GetString("crypto.positions.dlq.subject"),
+		dlqStream:     config.GetString("crypto.positions.dlq.stream"),
+		maxDeliver:    config.GetInt("crypto.positions.max.deliver"),
+	}
+}
+
+// Start crea los streams de cambios y de dead-letter si no existen y se suscribe
+// con un consumer durable, compartido por todas las réplicas
+func (c *positionConsumer) Start(ctx context.Context) error {
+	js, err := c.conn.JetStream()
+	if err != nil {
+		return err
+	}
+	c.js = js
+
+	if err := c.ensureStream(c.stream, c.subject); err != nil {
+		return err
+	}
+	if err := c.ensureStream(c.dlqStream, c.dlqSubject); err != nil {
+		return err
+	}
+
+	sub, err := js.PullSubscribe(c.subject, c.durable, nats.BindStream(c.stream), nats.ManualAck(), nats.AckExplicit())
+	if err != nil {
+		return fmt.Errorf("subscribing to %s: %w", c.subject, err)
+	}
+
+	c.routines.Go(func(ctx context.Context) {
+		for {
+			// Se pide de a un mensaje: un lote mayor espera a completarse o al
+			// timeout, y los mensajes recibidos podrían vencer su ack wait
+			fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+			msgs, err := sub.Fetch(1, nats.Context(fetchCtx))
+			cancel()
+
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, nats.ErrTimeout) {
+				c.logger.Error("error fetching position changes", zap.Error(err))
+				if !lifecycle.Sleep(ctx, time.Second) {
+					return
+				}
+				continue
+			}
+
+			for _, msg := range msgs {
+				c.handle(msg)
+			}
+		}
+	})
+
+	c.logger.Info("position consumer started",
+		zap.String("subject", c.subject),
+		zap.String("stream", c.stream),
+		zap.String("durable", c.durable))
+
+	return nil
+}
+
+func (c *positionConsumer) Stop(ctx context.Context) error {
+	return c.routines.Stop(ctx)
+}
+
+func (c *positionConsumer) ensureStream(name, subject string) error {
+	_, err := c.js.StreamInfo(name)
+	if !errors.Is(err, nats.ErrStreamNotFound) {
+		return err
+	}
+
+	if _, err := c.js.AddStream(&nats.StreamConfig{Name: name, Subjects: []string{subject}}); err != nil {
+		return fmt.Errorf("creating stream %s: %w", name, err)
+	}
+	c.logger.Info("position stream created", zap.String("stream", name), zap.String("subject", subject))
+
+	return nil
+}
+
+// handle aplica el cambio. Los mensajes inválidos o que dejarían una cantidad
+// negativa van al dead-letter subject; ante otros errores se reintentan, hasta
+// maxDeliver entregas.
+func (c *positionConsumer) handle(msg *nats.Msg) {
+	change := model.PositionChange{}
+	err := json.Unmarshal(msg.Data, &change)
+	if err != nil {
+		c.deadLetter(msg, fmt.Errorf("invalid message: %w", err))
+		return
+	}
+
+	if change.MessageID == "" {
+		change.MessageID = msg.Header.Get(nats.MsgIdHdr)
+	}
+
+	err = c.walletService.ApplyPositionChange(change)
+	switch {
+	case err == nil:
+		c.ack(msg)
+	case errors.Is(err, model.ErrInvalidPositionChange), errors.Is(err, model.ErrNegativePosition):
+		c.deadLetter(msg, err)
+	default:
+		meta, metaErr := msg.Metadata()
+		if metaErr == nil && c.maxDeliver > 0 && meta.NumDelivered >= uint64(c.maxDeliver) {
+			c.deadLetter(msg, err)
+			return
+		}
+
+		// Sin ack, el servidor lo reenvía al vencer el ack wait del consumer
+		c.logger.Warn("error applying position change, will retry",
+			zap.String("messageId", change.MessageID), zap.Error(err))
+	}
+}
+
+// deadLetter publica el mensaje original en el dead-letter subject con el
+// motivo y, una vez confirmado por el stream de dead-letter, lo descarta del
+// stream. Si la publicación falla, el mensaje se reenvía al vencer el ack wait.
+func (c *positionConsumer) deadLetter(msg *nats.Msg, reason error) {
+	c.logger.Warn("position message sent to dead-letter subject",
+		zap.String("subject", c.dlqSubject), zap.Error(reason))
+
+	dlq := nats.NewMsg(c.dlqSubject)
+	dlq.Data = msg.Data
+	for key, values := range msg.Header {
+		dlq.Header[key] = values
+	}
+	dlq.Header.Set(ErrorHeader, reason.Error())
+	dlq.Header.Set(SubjectHeader, msg.Subject)
+
+	if _, err := c.js.PublishMsg(dlq); err != nil {
+		c.logger.Error("error publishing to dead-letter subject", zap.Error(err))
+		return
+	}
+
+	if err := msg.Term(); err != nil {
+		c.logger.Error("error terminating position message", zap.Error(err))
+	}
+}
+
+func (c *positionConsumer) ack(msg *nats.Msg) {
+	if err := msg.Ack(); err != nil {
+		c.logger.Error("error acknowledging position message", zap.Error(err))
+	}
+}
@@ -0,0 +1,161 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startJetStream inicia un servidor NATS embebido con JetStream y devuelve una
+// conexión a él
+func startJetStream(t *testing.T) *nats.Conn {
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	require.NoError(t, err)
+
+	go ns.Start()
+	require.True(t, ns.ReadyForConnections(5*time.Second))
+	t.Cleanup(ns.Shutdown)
+
+	conn, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	return conn
+}
+
+func newConfig(t *testing.T) *config.Config {
+	t.Setenv("MTZ_CRYPTO_POSITIONS_SUBJECT", "positions")
+	t.Setenv("MTZ_CRYPTO_POSITIONS_STREAM", "POSITIONS")
+	t.Setenv("MTZ_CRYPTO_POSITIONS_DURABLE", "mtz-crypto")
+	t.Setenv("MTZ_CRYPTO_POSITIONS_DLQ_SUBJECT", "dlq.positions")
+	t.Setenv("MTZ_CRYPTO_POSITIONS_DLQ_STREAM", "POSITIONS_DLQ")
+	t.Setenv("MTZ_CRYPTO_POSITIONS_MAX_DELIVER", "2")
+
+	return config.NewConfig(&flag.FlagSet{})
+}
+
+func TestPositionConsumerAppliesChanges(t *testing.T) {
+	conn := startJetStream(t)
+	walletServiceMock := new(mocks.WalletService)
+	applied := make(chan model.PositionChange, 2)
+	walletServiceMock.On("ApplyPositionChange", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		applied <- args.Get(0).(model.PositionChange)
+	})
+
+	c := NewPositionConsumer(newConfig(t), zap.NewNop(), conn, walletServiceMock)
+	require.NoError(t, c.Start(context.Background()))
+	defer func() { assert.NoError(t, c.Stop(context.Background())) }()
+
+	require.NoError(t, conn.Publish("positions",
+		[]byte(`{"id":"msg1","walletId":"wallet1","symbol":"BTCUSD","quantity":"1.5"}`)))
+	assert.Equal(t, model.PositionChange{
+		MessageID: "msg1",
+		WalletID:  "wallet1",
+		Symbol:    "BTCUSD",
+		Quantity:  decimal.NullDecimal{Decimal: decimal.RequireFromString("1.5"), Valid: true},
+	}, <-applied)
+
+	// Sin id en el mensaje se usa el header de deduplicación de NATS
+	msg := nats.NewMsg("positions")
+	msg.Header.Set(nats.MsgIdHdr, "msg2")
+	msg.Data = []byte(`{"walletId":"wallet1","symbol":"ETHUSD","delta":"-0.5"}`)
+	require.NoError(t, conn.PublishMsg(msg))
+	assert.Equal(t, "msg2", (<-applied).MessageID)
+
+	// Los mensajes aplicados se confirman
+	js, err := conn.JetStream()
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		info, err := js.ConsumerInfo("POSITIONS", "mtz-crypto")
+		return err == nil && info.AckFloor.Stream == 2 && info.NumAckPending == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPositionConsumerDeadLetters(t *testing.T) {
+	conn := startJetStream(t)
+	dlq, err := conn.SubscribeSync("dlq.positions")
+	require.NoError(t, err)
+
+	// Consumer con ack wait corto para que los reintentos sean rápidos
+	js, err := conn.JetStream()
+	require.NoError(t, err)
+	_, err = js.AddStream(&nats.StreamConfig{Name: "POSITIONS", Subjects: []string{"positions"}})
+	require.NoError(t, err)
+	_, err = js.AddConsumer("POSITIONS", &nats.ConsumerConfig{
+		Durable:   "mtz-crypto",
+		AckPolicy: nats.AckExplicitPolicy,
+		AckWait:   100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	walletServiceMock := new(mocks.WalletService)
+	walletServiceMock.On("ApplyPositionChange", mock.MatchedBy(func(change model.PositionChange) bool {
+		return change.MessageID == "invalid"
+	})).Return(model.ErrInvalidPositionChange)
+	walletServiceMock.On("ApplyPositionChange", mock.MatchedBy(func(change model.PositionChange) bool {
+		return change.MessageID == "negative"
+	})).Return(model.ErrNegativePosition)
+	walletServiceMock.On("ApplyPositionChange", mock.MatchedBy(func(change model.PositionChange) bool {
+		return change.MessageID == "failing"
+	})).Return(errors.New("db down")).Twice()
+
+	c := NewPositionConsumer(newConfig(t), zap.NewNop(), conn, walletServiceMock)
+	require.NoError(t, c.Start(context.Background()))
+	defer func() { assert.NoError(t, c.Stop(context.Background())) }()
+
+	// Mensaje que no se puede decodificar
+	require.NoError(t, conn.Publish("positions", []byte(`not json`)))
+	received, err := dlq.NextMsg(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "not json", string(received.Data))
+	assert.Contains(t, received.Header.Get(ErrorHeader), "invalid message")
+	assert.Equal(t, "positions", received.Header.Get(SubjectHeader))
+
+	// Mensaje inválido
+	require.NoError(t, conn.Publish("positions", []byte(`{"id":"invalid","walletId":"wallet1"}`)))
+	received, err = dlq.NextMsg(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, model.ErrInvalidPositionChange.Error(), received.Header.Get(ErrorHeader))
+
+	// Mensaje que dejaría una cantidad negativa
+	require.NoError(t, conn.Publish("positions", []byte(`{"id":"negative","walletId":"wallet1","symbol":"BTCUSD","delta":"-5"}`)))
+	received, err = dlq.NextMsg(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, model.ErrNegativePosition.Error(), received.Header.Get(ErrorHeader))
+
+	// Mensaje que falla en todas las entregas
+	require.NoError(t, conn.Publish("positions", []byte(`{"id":"failing","walletId":"wallet1","symbol":"BTCUSD","delta":"1"}`)))
+	received, err = dlq.NextMsg(2 * time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "db down", received.Header.Get(ErrorHeader))
+
+	// Los mensajes quedan guardados en el stream de dead-letter y se descartan
+	// del stream de cambios
+	info, err := js.StreamInfo("POSITIONS_DLQ")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), info.State.Msgs)
+	assert.Eventually(t, func() bool {
+		info, err := js.ConsumerInfo("POSITIONS", "mtz-crypto")
+		return err == nil && info.NumAckPending == 0 && info.NumPending == 0
+	}, time.Second, 10*time.Millisecond)
+
+	walletServiceMock.AssertExpectations(t)
+}
@@ -0,0 +1,79 @@
+package consumer
+
+import (
+	"context"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"go.uber.org/zap"
+)
+
+// ProcessedMessagesPruner elimina periódicamente los mensajes aplicados más
+// antiguos que la retención. La retención debe superar el tiempo durante el que
+// un mensaje puede volver a entregarse; pasado ese tiempo ya no hace falta
+// recordarlo para no aplicarlo dos veces.
+type ProcessedMessagesPruner interface {
+	lifecycle.Component
+}
+
+type processedMessagesPruner struct {
+	logger    *zap.Logger
+	store     store.ProcessedMessageStore
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+	routines  lifecycle.Group
+}
+
+func NewProcessedMessagesPruner(
+	logger *zap.Logger,
+	processedMessageStore store.ProcessedMessageStore,
+	retention time.Duration,
+	interval time.Duration,
+) ProcessedMessagesPruner {
+	return &processedMessagesPruner{
+		logger:    logger,
+		store:     processedMessageStore,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+func (p *processedMessagesPruner) Start(ctx context.Context) error {
+	if p.retention <= 0 || p.interval <= 0 {
+		return nil
+	}
+
+	p.routines.Go(func(ctx context.Context) {
+		for {
+			p.prune()
+			if !lifecycle.Sleep(ctx, p.interval) {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (p *processedMessagesPruner) Stop(ctx context.Context) error {
+	return p.routines.Stop(ctx)
+}
+
+func (p *processedMessagesPruner) prune() {
+	before := p.now().Add(-p.retention)
+
+	deleted, err := p.store.DeleteProcessedBefore(before)
+	if err != nil {
+		p.logger.Error("error pruning processed messages", zap.Error(err))
+		return
+	}
+
+	if deleted > 0 {
+		p.logger.Info("processed messages pruned",
+			zap.Int64("deleted", deleted),
+			zap.Time("before", before))
+	}
+}
@@ -0,0 +1,46 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProcessedMessagesPruner(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	pruned := make(chan time.Time, 10)
+
+	storeMock := new(mocks.ProcessedMessageStore)
+	storeMock.On("DeleteProcessedBefore", now.Add(-24*time.Hour)).Return(int64(3), nil).Once().
+		Run(func(args mock.Arguments) { pruned <- args.Get(0).(time.Time) })
+	storeMock.On("DeleteProcessedBefore", now.Add(-24*time.Hour)).Return(int64(0), errors.New("db down")).
+		Run(func(args mock.Arguments) { pruned <- args.Get(0).(time.Time) })
+
+	p := NewProcessedMessagesPruner(zap.NewNop(), storeMock, 24*time.Hour, 10*time.Millisecond).(*processedMessagesPruner)
+	p.now = func() time.Time { return now }
+	require.NoError(t, p.Start(context.Background()))
+
+	// Se eliminan al iniciar y luego en cada intervalo, aunque falle una vez
+	assert.Equal(t, now.Add(-24*time.Hour), <-pruned)
+	assert.Equal(t, now.Add(-24*time.Hour), <-pruned)
+	assert.Equal(t, now.Add(-24*time.Hour), <-pruned)
+	assert.NoError(t, p.Stop(context.Background()))
+}
+
+func TestProcessedMessagesPrunerDisabled(t *testing.T) {
+	storeMock := new(mocks.ProcessedMessageStore)
+
+	p := NewProcessedMessagesPruner(zap.NewNop(), storeMock, 0, time.Millisecond)
+	require.NoError(t, p.Start(context.Background()))
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, p.Stop(context.Background()))
+
+	storeMock.AssertNotCalled(t, "DeleteProcessedBefore", mock.Anything)
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/pkg/health"
+)
+
+type HealthController interface {
+	Livez(ctx *gin.Context)
+	Readyz(ctx *gin.Context)
+	GetHealth(ctx *gin.Context)
+}
+
+type healthController struct {
+	registry *health.Registry
+}
+
+func NewHealthController(registry *health.Registry) HealthController {
+	return &healthController{
+		registry: registry,
+	}
+}
+
+func (c *healthController) Livez(ctx *gin.Context) {
+	if !c.registry.Live() {
+		ctx.String(http.StatusServiceUnavailable, health.StatusDown)
+		return
+	}
+
+	ctx.String(http.StatusOK, health.StatusUp)
+}
+
+func (c *healthController) Readyz(ctx *gin.Context) {
+	report := c.registry.Ready(ctx.Request.Context())
+	if report.Status != health.StatusUp {
+		ctx.String(http.StatusServiceUnavailable, report.Status)
+		return
+	}
+
+	ctx.String(http.StatusOK, report.Status)
+}
+
+// GetHealth detalle del estado de cada componente
+func (c *healthController) GetHealth(ctx *gin.Context) {
+	report := c.registry.Ready(ctx.Request.Context())
+	if report.Status != health.StatusUp {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
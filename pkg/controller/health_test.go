@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHealthRouter(registry *health.Registry) *gin.Engine {
+	healthController := NewHealthController(registry)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/livez", healthController.Livez)
+	r.GET("/readyz", healthController.Readyz)
+	r.GET("/health", healthController.GetHealth)
+
+	return r
+}
+
+func TestHealthController(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	registry := health.NewRegistry(time.Second)
+	registry.AddCheck("db", func(ctx context.Context) error { return dbErr }, true)
+	provider := registry.Component("provider.cryptonator", false)
+	provider.Success()
+	r := newHealthRouter(registry)
+
+	// Liveness no depende del estado de los componentes
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/livez", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/readyz", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/health", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var report health.Report
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Len(t, report.Components, 2)
+	assert.Equal(t, "db", report.Components[0].Name)
+	assert.Equal(t, health.StatusDown, report.Components[0].Status)
+	assert.Equal(t, "connection refused", report.Components[0].LastErrorMessage)
+	assert.NotNil(t, report.Components[0].LastError)
+	assert.Equal(t, "provider.cryptonator", report.Components[1].Name)
+	assert.Equal(t, health.StatusUp, report.Components[1].Status)
+	assert.NotNil(t, report.Components[1].LastSuccess)
+
+	dbErr = nil
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/readyz", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/service"
+	"go.uber.org/zap"
+)
+
+type IndexController interface {
+	GetIndex(ctx *gin.Context)
+	GetIndexHistory(ctx *gin.Context)
+}
+
+type indexController struct {
+	logger       *zap.Logger
+	indexService service.IndexService
+}
+
+func NewIndexController(
+	logger *zap.Logger,
+	indexService service.IndexService,
+) IndexController {
+	return &indexController{
+		logger:       logger,
+		indexService: indexService,
+	}
+}
+
+func (c *indexController) GetIndex(ctx *gin.Context) {
+	symbol := ctx.Param("symbol")
+
+	resp, err := c.indexService.GetIndex(symbol)
+	if err != nil {
+		c.abortWithError(ctx, symbol, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *indexController) GetIndexHistory(ctx *gin.Context) {
+	symbol := ctx.Param("symbol")
+
+	limit := 0
+	if limitStr, found := ctx.GetQuery("limit"); found {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": model.ErrInvalidLimit.Error()},
+			)
+			return
+		}
+	}
+
+	resp, err := c.indexService.GetIndexHistory(symbol, limit)
+	if err != nil {
+		c.abortWithError(ctx, symbol, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *indexController) abortWithError(ctx *gin.Context, symbol string, err error) {
+	if errors.Is(err, model.ErrIndexNotFound) {
+		ctx.AbortWithStatusJSON(
+			http.StatusNotFound,
+			gin.H{"error": model.ErrIndexNotFound.Error()},
+		)
+		return
+	}
+
+	c.logger.Error(
+		"error retrieving index",
+		zap.String("symbol", symbol),
+		zap.Error(err),
+	)
+
+	ctx.AbortWithStatusJSON(
+		http.StatusInternalServerError,
+		gin.H{"error": model.ErrUnexpected.Error()},
+	)
+}
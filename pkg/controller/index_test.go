@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newIndexRouter(indexService *mocks.IndexService) *gin.Engine {
+	indexController := NewIndexController(zap.NewNop(), indexService)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/index/:symbol", indexController.GetIndex)
+	r.GET("/index/:symbol/history", indexController.GetIndexHistory)
+
+	return r
+}
+
+func TestIndexControllerGetIndex(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339, "2021-08-03T12:34:56Z")
+	svcResp := model.GetIndexResponse{
+		Symbol:   "TOP4EW",
+		Level:    decimal.NullDecimal{Decimal: decimal.RequireFromString("105.5"), Valid: true},
+		DateTime: &ts,
+		Divisor:  decimal.NewFromInt(1),
+		Constituents: []model.IndexConstituent{{
+			Symbol:    "BTCUSD",
+			Weight:    decimal.NullDecimal{Decimal: decimal.RequireFromString("0.25"), Valid: true},
+			Units:     decimal.NullDecimal{Decimal: decimal.RequireFromString("0.0005"), Valid: true},
+			LastPrice: decimal.NullDecimal{Decimal: decimal.RequireFromString("50000"), Valid: true},
+		}},
+	}
+
+	indexServiceMock := new(mocks.IndexService)
+	indexServiceMock.On("GetIndex", "TOP4EW").Return(svcResp, nil)
+	indexServiceMock.On("GetIndex", "UNKNOWN").Return(model.GetIndexResponse{}, model.ErrIndexNotFound)
+	r := newIndexRouter(indexServiceMock)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/index/TOP4EW", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"symbol":"TOP4EW",
+		"level":"105.5",
+		"dateTime":"2021-08-03T12:34:56Z",
+		"divisor":"1",
+		"constituents":[{"symbol":"BTCUSD","weight":"0.25","units":"0.0005","lastPrice":"50000"}]
+	}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/index/UNKNOWN", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"index not found"}`, w.Body.String())
+	indexServiceMock.AssertExpectations(t)
+}
+
+func TestIndexControllerGetIndexHistory(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339, "2021-08-03T12:34:56Z")
+	svcResp := model.GetIndexHistoryResponse{
+		Symbol:  "TOP4EW",
+		History: []model.IndexLevel{{Level: decimal.RequireFromString("100"), DateTime: ts}},
+	}
+
+	indexServiceMock := new(mocks.IndexService)
+	indexServiceMock.On("GetIndexHistory", "TOP4EW", 10).Return(svcResp, nil)
+	r := newIndexRouter(indexServiceMock)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/index/TOP4EW/history?limit=10", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"symbol":"TOP4EW","history":[{"level":"100","dateTime":"2021-08-03T12:34:56Z"}]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/index/TOP4EW/history?limit=abc", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	indexServiceMock.AssertExpectations(t)
+}
@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/pkg/events"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/service"
+	"go.uber.org/zap"
+)
+
+// csvContentType tipo de las respuestas en CSV
+const csvContentType = "text/csv"
+
+type MarketDataController interface {
+	GetMarketData(ctx *gin.Context)
+	GetMarketDataBySymbol(ctx *gin.Context)
+	GetStatus(ctx *gin.Context)
+	Stream(ctx *gin.Context)
+}
+
+type marketDataController struct {
+	logger            *zap.Logger
+	freshnessService  service.FreshnessService
+	mdService         service.MarketDataService
+	heartbeatInterval time.Duration
+}
+
+func NewMarketDataController(
+	logger *zap.Logger,
+	freshnessService service.FreshnessService,
+	mdService service.MarketDataService,
+	heartbeatInterval time.Duration,
+) MarketDataController {
+	return &marketDataController{
+		logger:            logger,
+		freshnessService:  freshnessService,
+		mdService:         mdService,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// GetMarketData último precio de todos los símbolos, en JSON o CSV
+func (c *marketDataController) GetMarketData(ctx *gin.Context) {
+	format, ok := c.responseFormat(ctx)
+	if !ok {
+		return
+	}
+
+	mds, err := c.mdService.List()
+	if err != nil {
+		c.logger.Error("error listing market data", zap.Error(err))
+
+		ctx.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			gin.H{"error": model.ErrUnexpected.Error()},
+		)
+		return
+	}
+
+	resp := make([]model.MarketDataResponse, 0, len(mds))
+	for _, md := range mds {
+		resp = append(resp, newMarketDataResponse(md))
+	}
+
+	if format == "csv" {
+		c.writeCSV(ctx, resp...)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetMarketDataBySymbol último precio del símbolo, en JSON o CSV
+func (c *marketDataController) GetMarketDataBySymbol(ctx *gin.Context) {
+	format, ok := c.responseFormat(ctx)
+	if !ok {
+		return
+	}
+
+	symbol := ctx.Param("symbol")
+	md, err := c.mdService.GetMD(symbol)
+	if errors.Is(err, model.ErrSymbolNotFound) {
+		ctx.AbortWithStatusJSON(
+			http.StatusNotFound,
+			gin.H{"error": model.ErrSymbolNotFound.Error()},
+		)
+		return
+	}
+	if err != nil {
+		c.logger.Error(
+			"error retrieving market data",
+			zap.String("symbol", symbol),
+			zap.Error(err),
+		)
+
+		ctx.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			gin.H{"error": model.ErrUnexpected.Error()},
+		)
+		return
+	}
+
+	resp := newMarketDataResponse(md)
+	if format == "csv" {
+		c.writeCSV(ctx, resp)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// responseFormat formato pedido en el parámetro format o, si no se indica, en el
+// header Accept. Responde 400 si el formato no es válido.
+func (c *marketDataController) responseFormat(ctx *gin.Context) (format string, ok bool) {
+	switch format := ctx.Query("format"); format {
+	case "json", "csv":
+		return format, true
+	case "":
+		if ctx.NegotiateFormat(gin.MIMEJSON, csvContentType) == csvContentType {
+			return "csv", true
+		}
+		return "json", true
+	default:
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": model.ErrInvalidFormat.Error()},
+		)
+		return "", false
+	}
+}
+
+// writeCSV responde una fila por símbolo; las fuentes se separan con ';'
+func (c *marketDataController) writeCSV(ctx *gin.Context, resp ...model.MarketDataResponse) {
+	buf := bytes.Buffer{}
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"symbol", "price", "dateTime", "sources"})
+	for _, md := range resp {
+		_ = w.Write([]string{
+			md.Symbol,
+			md.Price.String(),
+			md.DateTime.Format(time.RFC3339Nano),
+			strings.Join(md.Sources, ";"),
+		})
+	}
+	w.Flush()
+
+	ctx.Data(http.StatusOK, csvContentType+"; charset=utf-8", buf.Bytes())
+}
+
+func newMarketDataResponse(md model.MarketData) model.MarketDataResponse {
+	sources := md.Sources
+	if sources == nil {
+		sources = []string{}
+	}
+
+	return model.MarketDataResponse{
+		Symbol:   md.Symbol,
+		Price:    md.LastPrice,
+		DateTime: md.LastPriceDateTime,
+		Sources:  sources,
+	}
+}
+
+// GetStatus estado de actualización de cada símbolo
+func (c *marketDataController) GetStatus(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.freshnessService.Status())
+}
+
+// Stream envía por Server-Sent Events cada actualización de los símbolos
+// indicados en symbols (separados por coma; sin símbolos, todos). Al conectarse
+// se envía el último precio de cada símbolo, salvo los ya recibidos hasta el
+// evento indicado en Last-Event-ID. Un ID emitido por otra réplica, mayor a los
+// de este proceso, no omite ningún precio.
+func (c *marketDataController) Stream(ctx *gin.Context) {
+	symbols := []string{}
+	for _, symbol := range strings.Split(ctx.Query("symbols"), ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	var lastEventID uint64
+	if header := ctx.GetHeader("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": model.ErrInvalidLastEventID.Error()},
+			)
+			return
+		}
+		lastEventID = id
+	}
+
+	subscription := c.mdService.SubscribeAfter(lastEventID, symbols...)
+	defer subscription.Unsubscribe()
+
+	var heartbeat <-chan time.Time
+	if c.heartbeatInterval > 0 {
+		ticker := time.NewTicker(c.heartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case update, ok := <-subscription.C():
+			if !ok {
+				c.logger.Warn("market data stream closed",
+					zap.Strings("symbols", symbols), zap.Error(subscription.Err()))
+				return
+			}
+			ctx.Render(-1, sse.Event{
+				Id:    strconv.FormatUint(update.Seq, 10),
+				Event: "md",
+				Data:  events.NewMarketDataEvent(update.MarketData),
+			})
+		case <-heartbeat:
+			// Los comentarios mantienen viva la conexión y el cliente los ignora
+			if _, err := ctx.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/hub"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newMarketDataRouter(
+	freshnessService *mocks.FreshnessService,
+	mdService *mocks.MarketDataService,
+) *gin.Engine {
+	marketDataController := NewMarketDataController(zap.NewNop(), freshnessService, mdService, 20*time.Millisecond)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/marketdata", marketDataController.GetMarketData)
+	r.GET("/marketdata/status", marketDataController.GetStatus)
+	r.GET("/marketdata/stream", marketDataController.Stream)
+	r.GET("/marketdata/:symbol", marketDataController.GetMarketDataBySymbol)
+
+	return r
+}
+
+func TestMarketDataControllerGetStatus(t *testing.T) {
+	lastUpdate, _ := time.Parse(time.RFC3339, "2021-08-10T15:00:00Z")
+	svcResp := model.GetMarketDataStatusResponse{
+		StaleThresholdSeconds: 60,
+		Symbols: []model.SymbolStatus{{
+			Symbol:              "BTCUSD",
+			Status:              model.SymbolStatusStale,
+			LastUpdate:          &lastUpdate,
+			AgeSeconds:          120,
+			ConsecutiveFailures: 3,
+			LastError:           "timeout",
+			Gaps:                1,
+			LastGapSeconds:      90,
+			MaxGapSeconds:       90,
+		}},
+	}
+
+	freshnessServiceMock := new(mocks.FreshnessService)
+	freshnessServiceMock.On("Status").Return(svcResp)
+	r := newMarketDataRouter(freshnessServiceMock, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/marketdata/status", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"staleThresholdSeconds":60,
+		"symbols":[{
+			"symbol":"BTCUSD",
+			"status":"stale",
+			"lastUpdate":"2021-08-10T15:00:00Z",
+			"ageSeconds":120,
+			"consecutiveFailures":3,
+			"lastError":"timeout",
+			"gaps":1,
+			"lastGapSeconds":90,
+			"maxGapSeconds":90
+		}]
+	}`, w.Body.String())
+}
+
+func TestMarketDataControllerGetMarketData(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339, "2021-08-10T15:00:00Z")
+	mdServiceMock := new(mocks.MarketDataService)
+	mdServiceMock.On("List").Return([]model.MarketData{
+		{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("45000.5"), LastPriceDateTime: ts, Sources: []string{"binance", "cryptonator"}},
+		{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("3000"), LastPriceDateTime: ts},
+	}, nil)
+	r := newMarketDataRouter(nil, mdServiceMock)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/marketdata", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[
+		{"symbol":"BTCUSD","price":"45000.5","dateTime":"2021-08-10T15:00:00Z","sources":["binance","cryptonator"]},
+		{"symbol":"ETHUSD","price":"3000","dateTime":"2021-08-10T15:00:00Z","sources":[]}
+	]`, w.Body.String())
+
+	// CSV con el parámetro format o con el header Accept
+	csv := "symbol,price,dateTime,sources\n" +
+		"BTCUSD,45000.5,2021-08-10T15:00:00Z,binance;cryptonator\n" +
+		"ETHUSD,3000,2021-08-10T15:00:00Z,\n"
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/marketdata?format=csv", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, csv, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/marketdata", nil)
+	req.Header.Set("Accept", "text/csv")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, csv, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/marketdata?format=xml", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"format must be json or csv"}`, w.Body.String())
+}
+
+func TestMarketDataControllerGetMarketDataBySymbol(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339, "2021-08-10T15:00:00Z")
+	mdServiceMock := new(mocks.MarketDataService)
+	mdServiceMock.On("GetMD", "BTCUSD").Return(model.MarketData{
+		Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("45000.5"), LastPriceDateTime: ts, Sources: []string{"binance"},
+	}, nil)
+	mdServiceMock.On("GetMD", "XXXUSD").Return(model.MarketData{}, model.ErrSymbolNotFound)
+	r := newMarketDataRouter(nil, mdServiceMock)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/marketdata/BTCUSD", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"symbol":"BTCUSD","price":"45000.5","dateTime":"2021-08-10T15:00:00Z","sources":["binance"]}`,
+		w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/marketdata/BTCUSD?format=csv", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "symbol,price,dateTime,sources\nBTCUSD,45000.5,2021-08-10T15:00:00Z,binance\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/marketdata/XXXUSD", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"symbol not found"}`, w.Body.String())
+}
+
+// trackedSubscription avisa cuando el controller se desuscribe
+type trackedSubscription struct {
+	hub.Subscription
+	unsubscribed chan struct{}
+}
+
+func (s *trackedSubscription) Unsubscribe() {
+	s.Subscription.Unsubscribe()
+	close(s.unsubscribed)
+}
+
+// openStream se conecta al stream y devuelve un lector de sus líneas
+func openStream(t *testing.T, url, lastEventID string) (*bufio.Reader, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return bufio.NewReader(resp.Body), cancel
+}
+
+// nextEvent lee el próximo evento, salteando los heartbeats
+func nextEvent(t *testing.T, reader *bufio.Reader) (event []string) {
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "" && len(event) > 0:
+			return event
+		case line == "", strings.HasPrefix(line, ":"):
+		default:
+			event = append(event, line)
+		}
+	}
+}
+
+func TestMarketDataControllerStream(t *testing.T) {
+	h := hub.NewHub(10, hub.DropPolicy, prometheus.NewRegistry())
+	ts := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	h.OnMD(model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("45000"), LastPriceDateTime: ts})
+
+	subscriptions := make(chan *trackedSubscription, 2)
+	mdServiceMock := new(mocks.MarketDataService)
+	mdServiceMock.On("SubscribeAfter", mock.Anything, "BTCUSD", "ETHUSD").Return(func(lastSeq uint64, symbols ...string) hub.Subscription {
+		s := &trackedSubscription{Subscription: h.SubscribeAfter(lastSeq, symbols...), unsubscribed: make(chan struct{})}
+		subscriptions <- s
+		return s
+	})
+
+	server := httptest.NewServer(newMarketDataRouter(nil, mdServiceMock))
+	defer server.Close()
+	url := server.URL + "/marketdata/stream?symbols=BTCUSD,%20ETHUSD"
+
+	// Al conectarse se recibe el último precio
+	reader, cancel := openStream(t, url, "")
+	event := nextEvent(t, reader)
+	require.Len(t, event, 3)
+	btcID := strings.TrimPrefix(event[0], "id:")
+	assert.Equal(t, "event:md", event[1])
+	assert.JSONEq(t, `{"version":1,"symbol":"BTCUSD","price":"45000","timestamp":"2021-08-10T15:00:00Z"}`,
+		strings.TrimPrefix(event[2], "data:"))
+
+	h.OnMD(model.MarketData{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("3000"), LastPriceDateTime: ts})
+	event = nextEvent(t, reader)
+	assert.Contains(t, event[2], `"symbol":"ETHUSD"`)
+
+	// Al desconectarse el cliente se cierra la suscripción
+	cancel()
+	select {
+	case <-(<-subscriptions).unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed")
+	}
+
+	// Al reconectarse con Last-Event-ID no se repiten los eventos ya recibidos
+	reader, cancel = openStream(t, url, btcID)
+	defer cancel()
+	event = nextEvent(t, reader)
+	assert.Contains(t, event[2], `"symbol":"ETHUSD"`)
+	h.OnMD(model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("45100"), LastPriceDateTime: ts})
+	event = nextEvent(t, reader)
+	assert.Contains(t, event[2], `"price":"45100"`)
+	cancel()
+
+	// Un Last-Event-ID de otra réplica, mayor a los de este proceso, recibe todos
+	// los precios y luego las actualizaciones
+	reader, cancel = openStream(t, url, "18446744073709551615")
+	defer cancel()
+	assert.Contains(t, nextEvent(t, reader)[2], `"price":"45100"`)
+	assert.Contains(t, nextEvent(t, reader)[2], `"symbol":"ETHUSD"`)
+	h.OnMD(model.MarketData{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("3100"), LastPriceDateTime: ts})
+	assert.Contains(t, nextEvent(t, reader)[2], `"price":"3100"`)
+}
+
+func TestMarketDataControllerStreamInvalidLastEventID(t *testing.T) {
+	r := newMarketDataRouter(nil, new(mocks.MarketDataService))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/marketdata/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"invalid Last-Event-ID"}`, w.Body.String())
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/service"
+	"go.uber.org/zap"
+)
+
+type SubscriptionController interface {
+	GetSubscriptions(ctx *gin.Context)
+	AddSubscription(ctx *gin.Context)
+	RemoveSubscription(ctx *gin.Context)
+	PauseSubscription(ctx *gin.Context)
+	ResumeSubscription(ctx *gin.Context)
+}
+
+type subscriptionController struct {
+	logger              *zap.Logger
+	subscriptionService service.SubscriptionService
+}
+
+func NewSubscriptionController(
+	logger *zap.Logger,
+	subscriptionService service.SubscriptionService,
+) SubscriptionController {
+	return &subscriptionController{
+		logger:              logger,
+		subscriptionService: subscriptionService,
+	}
+}
+
+// AdminAuth exige el token de administración en el header Authorization
+// ("Bearer <token>"). Sin token configurado se rechazan todos los requests.
+func AdminAuth(token string) gin.HandlerFunc {
+	expected := []byte("Bearer " + token)
+
+	return func(ctx *gin.Context) {
+		if token == "" {
+			ctx.AbortWithStatusJSON(
+				http.StatusServiceUnavailable,
+				gin.H{"error": model.ErrAdminDisabled.Error()},
+			)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(ctx.GetHeader("Authorization")), expected) != 1 {
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				gin.H{"error": model.ErrUnauthorized.Error()},
+			)
+		}
+	}
+}
+
+func (c *subscriptionController) GetSubscriptions(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	resp, err := c.subscriptionService.GetSubscriptions(provider)
+	if err != nil {
+		c.abortWithError(ctx, provider, "", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *subscriptionController) AddSubscription(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	var req model.AddSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": model.ErrInvalidSubscription.Error()},
+		)
+		return
+	}
+
+	resp, err := c.subscriptionService.AddSubscription(provider, req)
+	if err != nil {
+		c.abortWithError(ctx, provider, req.Symbol, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+func (c *subscriptionController) RemoveSubscription(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+	symbol := ctx.Param("symbol")
+
+	if err := c.subscriptionService.RemoveSubscription(provider, symbol); err != nil {
+		c.abortWithError(ctx, provider, symbol, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *subscriptionController) PauseSubscription(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+	symbol := ctx.Param("symbol")
+
+	resp, err := c.subscriptionService.PauseSubscription(provider, symbol)
+	if err != nil {
+		c.abortWithError(ctx, provider, symbol, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *subscriptionController) ResumeSubscription(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+	symbol := ctx.Param("symbol")
+
+	resp, err := c.subscriptionService.ResumeSubscription(provider, symbol)
+	if err != nil {
+		c.abortWithError(ctx, provider, symbol, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *subscriptionController) abortWithError(ctx *gin.Context, provider, symbol string, err error) {
+	switch {
+	case errors.Is(err, model.ErrProviderNotFound), errors.Is(err, model.ErrSubscriptionNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, model.ErrSubscriptionExists):
+		ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, model.ErrInvalidSubscription):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.logger.Error(
+		"error updating subscriptions",
+		zap.String("provider", provider),
+		zap.String("symbol", symbol),
+		zap.Error(err),
+	)
+
+	ctx.AbortWithStatusJSON(
+		http.StatusInternalServerError,
+		gin.H{"error": model.ErrUnexpected.Error()},
+	)
+}
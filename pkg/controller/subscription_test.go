@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const testAdminToken = "s3cret"
+
+func newSubscriptionRouter(subscriptionService *mocks.SubscriptionService, token string) *gin.Engine {
+	subscriptionController := NewSubscriptionController(zap.NewNop(), subscriptionService)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	admin := r.Group("/admin", AdminAuth(token))
+	admin.GET("/subscriptions/:provider", subscriptionController.GetSubscriptions)
+	admin.POST("/subscriptions/:provider", subscriptionController.AddSubscription)
+	admin.DELETE("/subscriptions/:provider/:symbol", subscriptionController.RemoveSubscription)
+	admin.POST("/subscriptions/:provider/:symbol/pause", subscriptionController.PauseSubscription)
+	admin.POST("/subscriptions/:provider/:symbol/resume", subscriptionController.ResumeSubscription)
+
+	return r
+}
+
+func TestSubscriptionControllerAdd(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339, "2021-08-10T15:00:00Z")
+	req := model.AddSubscriptionRequest{Symbol: "ETHUSD", ExternalSymbol: "eth-usd"}
+
+	subscriptionServiceMock := new(mocks.SubscriptionService)
+	subscriptionServiceMock.On("AddSubscription", "cryptonator", req).Return(model.Subscription{
+		Provider:       "cryptonator",
+		Symbol:         "ETHUSD",
+		ExternalSymbol: "eth-usd",
+		CreatedAt:      ts,
+		UpdatedAt:      ts,
+	}, nil).Once()
+	subscriptionServiceMock.On("AddSubscription", "cryptonator", req).Return(model.Subscription{}, model.ErrSubscriptionExists)
+	r := newSubscriptionRouter(subscriptionServiceMock, testAdminToken)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/admin/subscriptions/cryptonator",
+		strings.NewReader(`{"symbol":"ETHUSD","externalSymbol":"eth-usd"}`))
+	httpReq.Header.Set("Authorization", "Bearer "+testAdminToken)
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{
+		"provider":"cryptonator",
+		"symbol":"ETHUSD",
+		"externalSymbol":"eth-usd",
+		"paused":false,
+		"createdAt":"2021-08-10T15:00:00Z",
+		"updatedAt":"2021-08-10T15:00:00Z"
+	}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/admin/subscriptions/cryptonator",
+		strings.NewReader(`{"symbol":"ETHUSD","externalSymbol":"eth-usd"}`))
+	httpReq.Header.Set("Authorization", "Bearer "+testAdminToken)
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/admin/subscriptions/cryptonator", strings.NewReader(`not json`))
+	httpReq.Header.Set("Authorization", "Bearer "+testAdminToken)
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSubscriptionControllerPauseAndRemove(t *testing.T) {
+	subscriptionServiceMock := new(mocks.SubscriptionService)
+	subscriptionServiceMock.On("PauseSubscription", "cryptonator", "BTCUSD").
+		Return(model.Subscription{Provider: "cryptonator", Symbol: "BTCUSD", Paused: true}, nil)
+	subscriptionServiceMock.On("RemoveSubscription", "cryptonator", "BTCUSD").Return(nil)
+	subscriptionServiceMock.On("RemoveSubscription", "binance", "BTCUSD").Return(model.ErrProviderNotFound)
+	r := newSubscriptionRouter(subscriptionServiceMock, testAdminToken)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/subscriptions/cryptonator/BTCUSD/pause", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"paused":true`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/admin/subscriptions/cryptonator/BTCUSD", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/admin/subscriptions/binance/BTCUSD", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminAuth(t *testing.T) {
+	subscriptionServiceMock := new(mocks.SubscriptionService)
+	subscriptionServiceMock.On("GetSubscriptions", "cryptonator").
+		Return(model.GetSubscriptionsResponse{Provider: "cryptonator"}, nil)
+	r := newSubscriptionRouter(subscriptionServiceMock, testAdminToken)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/subscriptions/cryptonator", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/subscriptions/cryptonator", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminAuthWithoutToken(t *testing.T) {
+	r := newSubscriptionRouter(new(mocks.SubscriptionService), "")
+
+	// Sin token configurado la administración queda deshabilitada
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/subscriptions/cryptonator", strings.NewReader(`{"symbol":"ETHUSD"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"error":"admin token not configured"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/subscriptions/cryptonator", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
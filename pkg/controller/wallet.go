@@ -2,29 +2,69 @@ package controller
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/matbarofex/mtz-crypto/pkg/model"
 	"github.com/matbarofex/mtz-crypto/pkg/service"
 	"go.uber.org/zap"
 )
 
+// liveWriteTimeout tiempo máximo de escritura de una valuación en el WebSocket
+const liveWriteTimeout = 10 * time.Second
+
 type WalletController interface {
 	GetWalletValue(ctx *gin.Context)
+	GetWalletValueLive(ctx *gin.Context)
 }
 
 type walletController struct {
 	logger        *zap.Logger
 	walletService service.WalletService
+	walletWatcher service.WalletWatcher
+	upgrader      websocket.Upgrader
 }
 
+// NewWalletController allowedOrigins son los orígenes (ej. https://dashboard.example.com)
+// que, además del propio servicio, pueden abrir el WebSocket de /wallet/live
 func NewWalletController(
 	logger *zap.Logger,
 	walletService service.WalletService,
+	walletWatcher service.WalletWatcher,
+	allowedOrigins []string,
 ) WalletController {
 	return &walletController{
 		logger:        logger,
 		walletService: walletService,
+		walletWatcher: walletWatcher,
+		upgrader:      websocket.Upgrader{CheckOrigin: checkOrigin(allowedOrigins)},
+	}
+}
+
+// checkOrigin acepta los requests sin Origin (clientes que no son navegadores),
+// los del mismo origen y los de allowedOrigins. Evita que cualquier página abierta
+// en un navegador lea las valuaciones.
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.ToLower(strings.TrimRight(origin, "/"))] = true
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || allowed[strings.ToLower(origin)] {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+
+		return strings.EqualFold(u.Host, r.Host)
 	}
 }
 
@@ -59,3 +99,52 @@ func (c *walletController) GetWalletValue(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, resp)
 }
+
+// GetWalletValueLive envía por WebSocket la valuación de las wallets que indica
+// el cliente con mensajes {"subscribe":[...]} y {"unsubscribe":[...]}, cada vez
+// que cambia el precio de alguno de sus símbolos
+func (c *walletController) GetWalletValueLive(ctx *gin.Context) {
+	conn, err := c.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// Upgrade ya respondió el error al cliente
+		c.logger.Warn("error upgrading wallet live connection", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	watch := c.walletWatcher.Watch()
+	defer watch.Close()
+
+	// Los mensajes del cliente se leen en otra goroutine; las escrituras se hacen
+	// sólo en esta
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+
+		for {
+			req := model.WalletLiveRequest{}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			watch.Remove(req.Unsubscribe...)
+			watch.Add(req.Subscribe...)
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ctx.Request.Context().Done():
+			return
+		case update := <-watch.C():
+			if err := conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout)); err != nil {
+				return
+			}
+			if err := conn.WriteJSON(update); err != nil {
+				c.logger.Warn("error writing wallet value", zap.Error(err))
+				return
+			}
+		}
+	}
+}
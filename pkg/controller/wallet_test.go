@@ -1,16 +1,22 @@
 package controller
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/matbarofex/mtz-crypto/mocks"
 	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/service"
+	"github.com/matbarofex/mtz-crypto/pkg/store/memory"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -27,7 +33,7 @@ func TestWalletControllerOK(t *testing.T) {
 	walletServiceMock.On("GetWalletValue", svcReq).Return(svcResp, nil)
 
 	logger := zap.NewNop()
-	walletController := NewWalletController(logger, walletServiceMock)
+	walletController := NewWalletController(logger, walletServiceMock, nil, nil)
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
@@ -45,7 +51,7 @@ func TestWalletControllerOK(t *testing.T) {
 func TestWalletControllerWithoutWallet(t *testing.T) {
 	logger := zap.NewNop()
 	walletServiceMock := new(mocks.WalletService)
-	walletController := NewWalletController(logger, walletServiceMock)
+	walletController := NewWalletController(logger, walletServiceMock, nil, nil)
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
@@ -66,7 +72,7 @@ func TestWalletControllerWithoutValue(t *testing.T) {
 	walletServiceMock.On("GetWalletValue", svcReq).Return(svcResp, nil)
 
 	logger := zap.NewNop()
-	walletController := NewWalletController(logger, walletServiceMock)
+	walletController := NewWalletController(logger, walletServiceMock, nil, nil)
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
@@ -80,3 +86,96 @@ func TestWalletControllerWithoutValue(t *testing.T) {
 	assert.JSONEq(t, `{"walletId":"wallet1","value":null}`, w.Body.String())
 	walletServiceMock.AssertExpectations(t)
 }
+
+func TestWalletControllerLive(t *testing.T) {
+	logger := zap.NewNop()
+	mdService := service.NewMarketDataService(logger, memory.NewMarketDataStore())
+
+	walletStoreMock := new(mocks.WalletStore)
+	walletStoreMock.On("GetWallet", "wallet1").Return(model.Wallet{ID: "wallet1", Items: []model.WalletItem{
+		{Symbol: "BTCUSD", Quantity: decimal.RequireFromString("1")},
+	}}, nil)
+	walletStoreMock.On("GetWallet", "wallet2").Return(model.Wallet{ID: "wallet2", Items: []model.WalletItem{
+		{Symbol: "ETHUSD", Quantity: decimal.RequireFromString("1")},
+	}}, nil)
+	walletServiceMock := new(mocks.WalletService)
+	walletServiceMock.On("ValueWallet", "wallet1").Return(model.GetWalletValueResponse{
+		ID:    "wallet1",
+		Value: decimal.NullDecimal{Decimal: decimal.RequireFromString("100"), Valid: true},
+	}, nil)
+	walletServiceMock.On("ValueWallet", "wallet2").Return(model.GetWalletValueResponse{
+		ID:    "wallet2",
+		Value: decimal.NullDecimal{Decimal: decimal.RequireFromString("10"), Valid: true},
+	}, nil)
+
+	watcher := service.NewWalletWatcher(logger, walletStoreMock, walletServiceMock, mdService, 0, 0)
+	require.NoError(t, watcher.Start(context.Background()))
+	defer func() { assert.NoError(t, watcher.Stop(context.Background())) }()
+
+	walletController := NewWalletController(logger, walletServiceMock, watcher, []string{"https://dashboard.example.com"})
+	r := gin.Default()
+	r.GET("/wallet/live", walletController.GetWalletValueLive)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/wallet/live"
+
+	// Sólo se aceptan los navegadores del mismo origen o de los orígenes permitidos
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"https://evil.example.com"}})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	for _, origin := range []string{server.URL, "https://dashboard.example.com"} {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {origin}})
+		require.NoError(t, err)
+		conn.Close()
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	next := func() model.WalletValueUpdate {
+		update := model.WalletValueUpdate{}
+		require.NoError(t, conn.ReadJSON(&update))
+		return update
+	}
+
+	// Una misma conexión se suscribe a varias wallets
+	require.NoError(t, conn.WriteJSON(model.WalletLiveRequest{Subscribe: []string{"wallet1", "wallet2"}}))
+	assert.Equal(t, "wallet1", next().ID)
+	assert.Equal(t, "wallet2", next().ID)
+
+	// Al suscribirse nuevamente a una wallet se reenvía su valuación, lo que
+	// confirma que ya se procesó la desuscripción del mismo mensaje
+	require.NoError(t, conn.WriteJSON(model.WalletLiveRequest{
+		Subscribe:   []string{"wallet2"},
+		Unsubscribe: []string{"wallet1"},
+	}))
+	assert.Equal(t, "wallet2", next().ID)
+
+	// El cambio de precio de un símbolo envía sólo las wallets que lo contienen
+	mdChannel := make(model.MdChannel, 10)
+	mdService.ConsumeMD(mdChannel)
+	defer close(mdChannel)
+	mdChannel <- model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("100")}
+	mdChannel <- model.MarketData{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("10")}
+	update := next()
+	assert.Equal(t, "wallet2", update.ID)
+	assert.Equal(t, "10", update.Value.Decimal.String())
+	assert.Empty(t, update.Error)
+}
+
+func TestWalletControllerLiveRequiresWebSocket(t *testing.T) {
+	logger := zap.NewNop()
+	walletController := NewWalletController(logger, new(mocks.WalletService), nil, nil)
+
+	r := gin.Default()
+	r.GET("/wallet/live", walletController.GetWalletValueLive)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/wallet/live", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,285 @@
+package aggregator
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+const (
+	// ModeFailover publica el precio del proveedor de mayor prioridad que tenga datos vigentes
+	ModeFailover = "failover"
+	// ModeConsensus publica la mediana de los proveedores vigentes, descartando outliers
+	ModeConsensus = "consensus"
+)
+
+// Aggregator combina la market data de varios proveedores en un único channel
+type Aggregator interface {
+	lifecycle.Component
+	// Channel devuelve el channel en el que debe publicar un proveedor
+	Channel(provider string) model.MdChannel
+}
+
+type aggregator struct {
+	logger         *zap.Logger
+	mode           string
+	priority       []string
+	symbolPriority map[string][]string
+	maxAge         time.Duration
+	minSources     int
+	maxDeviation   decimal.Decimal
+	mdChannel      model.MdChannel
+	now            func() time.Time
+
+	mu     sync.Mutex
+	inputs map[string]model.MdChannel
+	quotes map[string]map[string]quote
+
+	routines lifecycle.Group
+}
+
+// quote último precio recibido de un proveedor para un símbolo
+type quote struct {
+	md       model.MarketData
+	received time.Time
+}
+
+func NewAggregator(
+	config *config.Config,
+	logger *zap.Logger,
+	mdChannel model.MdChannel,
+) Aggregator {
+	symbolPriority := make(map[string][]string)
+	for _, entry := range config.GetStringSlice("crypto.aggregator.symbol.priority") {
+		fields := strings.Split(entry, ";")
+		if len(fields) < 2 {
+			logger.Warn("invalid symbol priority entry", zap.String("entry", entry))
+			continue
+		}
+		symbolPriority[fields[0]] = fields[1:]
+	}
+
+	// El consenso necesita al menos un precio
+	minSources := config.GetInt("crypto.aggregator.min.sources")
+	if minSources < 1 {
+		logger.Warn("invalid crypto.aggregator.min.sources, using 1", zap.Int("minSources", minSources))
+		minSources = 1
+	}
+
+	return &aggregator{
+		logger:         logger,
+		mode:           config.GetString("crypto.aggregator.mode"),
+		priority:       config.GetStringSlice("crypto.aggregator.priority"),
+		symbolPriority: symbolPriority,
+		maxAge:         config.GetDuration("crypto.aggregator.max.age"),
+		minSources:     minSources,
+		maxDeviation:   decimal.NewFromFloat(config.GetFloat64("crypto.aggregator.max.deviation")),
+		mdChannel:      mdChannel,
+		now:            time.Now,
+		inputs:         make(map[string]model.MdChannel),
+		quotes:         make(map[string]map[string]quote),
+	}
+}
+
+func (a *aggregator) Channel(provider string) model.MdChannel {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ch, ok := a.inputs[provider]
+	if !ok {
+		ch = make(model.MdChannel)
+		a.inputs[provider] = ch
+	}
+
+	return ch
+}
+
+func (a *aggregator) Start(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for provider, ch := range a.inputs {
+		provider, ch := provider, ch
+		a.routines.Go(func(ctx context.Context) {
+			for {
+				var md model.MarketData
+				select {
+				case md = <-ch:
+				case <-ctx.Done():
+					return
+				}
+
+				rs, ok := a.aggregate(provider, md)
+				if !ok {
+					continue
+				}
+
+				select {
+				case a.mdChannel <- rs:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	}
+
+	a.logger.Info("aggregator started",
+		zap.String("mode", a.mode),
+		zap.Int("providers", len(a.inputs)))
+
+	return nil
+}
+
+// Stop deja de reenviar market data. Los proveedores deben detenerse antes.
+func (a *aggregator) Stop(ctx context.Context) error {
+	return a.routines.Stop(ctx)
+}
+
+// aggregate registra el precio recibido y determina si corresponde publicar
+func (a *aggregator) aggregate(provider string, md model.MarketData) (rs model.MarketData, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	byProvider, found := a.quotes[md.Symbol]
+	if !found {
+		byProvider = make(map[string]quote)
+		a.quotes[md.Symbol] = byProvider
+	}
+	byProvider[provider] = quote{md: md, received: a.now()}
+
+	if a.mode == ModeConsensus {
+		return a.consensus(md.Symbol, byProvider)
+	}
+
+	return a.failover(provider, md, byProvider)
+}
+
+// failover publica el precio sólo si proviene del proveedor vigente de mayor prioridad
+func (a *aggregator) failover(provider string, md model.MarketData, byProvider map[string]quote) (rs model.MarketData, ok bool) {
+	for _, p := range a.providersFor(md.Symbol, byProvider) {
+		q, found := byProvider[p]
+		if !found || !a.isFresh(q) {
+			continue
+		}
+
+		if p != provider {
+			return rs, false
+		}
+
+		rs = md
+		rs.Sources = []string{provider}
+		return rs, true
+	}
+
+	return rs, false
+}
+
+// consensus publica la mediana de los precios vigentes, descartando los que se
+// desvían de la mediana más que el máximo configurado
+func (a *aggregator) consensus(symbol string, byProvider map[string]quote) (rs model.MarketData, ok bool) {
+	fresh := map[string]quote{}
+	for p, q := range byProvider {
+		if a.isFresh(q) {
+			fresh[p] = q
+		}
+	}
+
+	if len(fresh) == 0 || len(fresh) < a.minSources {
+		a.logger.Debug("not enough sources for consensus",
+			zap.String("symbol", symbol),
+			zap.Int("sources", len(fresh)))
+		return rs, false
+	}
+
+	prices := make([]decimal.Decimal, 0, len(fresh))
+	for _, q := range fresh {
+		prices = append(prices, q.md.LastPrice)
+	}
+	med := median(prices)
+
+	accepted := []string{}
+	prices = prices[:0]
+	var timestamp time.Time
+	for p, q := range fresh {
+		if !med.IsZero() && q.md.LastPrice.Sub(med).Abs().Div(med).GreaterThan(a.maxDeviation) {
+			a.logger.Warn("outlier price discarded",
+				zap.String("symbol", symbol),
+				zap.String("provider", p),
+				zap.String("price", q.md.LastPrice.String()),
+				zap.String("median", med.String()))
+			continue
+		}
+
+		accepted = append(accepted, p)
+		prices = append(prices, q.md.LastPrice)
+		if q.md.LastPriceDateTime.After(timestamp) {
+			timestamp = q.md.LastPriceDateTime
+		}
+	}
+
+	if len(accepted) == 0 || len(accepted) < a.minSources {
+		a.logger.Warn("not enough sources after discarding outliers",
+			zap.String("symbol", symbol),
+			zap.Strings("accepted", accepted))
+		return rs, false
+	}
+
+	sort.Strings(accepted)
+	rs = model.MarketData{
+		Symbol:            symbol,
+		LastPrice:         median(prices),
+		LastPriceDateTime: timestamp,
+		Sources:           accepted,
+	}
+
+	return rs, true
+}
+
+// providersFor devuelve los proveedores de un símbolo ordenados por prioridad.
+// Los proveedores sin prioridad configurada quedan al final, en orden alfabético.
+func (a *aggregator) providersFor(symbol string, byProvider map[string]quote) []string {
+	priority, found := a.symbolPriority[symbol]
+	if !found {
+		priority = a.priority
+	}
+
+	listed := make(map[string]bool, len(priority))
+	for _, p := range priority {
+		listed[p] = true
+	}
+
+	rest := []string{}
+	for p := range byProvider {
+		if !listed[p] {
+			rest = append(rest, p)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(append([]string{}, priority...), rest...)
+}
+
+func (a *aggregator) isFresh(q quote) bool {
+	return a.maxAge <= 0 || a.now().Sub(q.received) <= a.maxAge
+}
+
+// median calcula la mediana de una lista de precios no vacía
+func median(prices []decimal.Decimal) decimal.Decimal {
+	sorted := append([]decimal.Decimal{}, prices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+
+	return sorted[n/2-1].Add(sorted[n/2]).Div(decimal.NewFromInt(2))
+}
@@ -0,0 +1,126 @@
+package aggregator
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newTestAggregator(mode string, now *time.Time) *aggregator {
+	return &aggregator{
+		logger:         zap.NewNop(),
+		mode:           mode,
+		priority:       []string{"primary", "secondary"},
+		symbolPriority: map[string][]string{"ETHUSD": {"secondary", "primary"}},
+		maxAge:         time.Minute,
+		minSources:     2,
+		maxDeviation:   decimal.RequireFromString("0.05"),
+		now:            func() time.Time { return *now },
+		inputs:         make(map[string]model.MdChannel),
+		quotes:         make(map[string]map[string]quote),
+	}
+}
+
+func newMD(symbol, price string) model.MarketData {
+	return model.MarketData{
+		Symbol:            symbol,
+		LastPrice:         decimal.RequireFromString(price),
+		LastPriceDateTime: time.Unix(1_628_610_304, 0),
+	}
+}
+
+func TestFailoverPriority(t *testing.T) {
+	now := time.Now()
+	a := newTestAggregator(ModeFailover, &now)
+
+	md, ok := a.aggregate("secondary", newMD("BTCUSD", "100"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"secondary"}, md.Sources)
+
+	md, ok = a.aggregate("primary", newMD("BTCUSD", "101"))
+	assert.True(t, ok)
+	assert.Equal(t, "101", md.LastPrice.String())
+	assert.Equal(t, []string{"primary"}, md.Sources)
+
+	// Mientras primary esté vigente se ignoran los precios de secondary
+	_, ok = a.aggregate("secondary", newMD("BTCUSD", "102"))
+	assert.False(t, ok)
+
+	// Vencido primary, secondary toma su lugar
+	now = now.Add(2 * time.Minute)
+	md, ok = a.aggregate("secondary", newMD("BTCUSD", "103"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"secondary"}, md.Sources)
+}
+
+func TestFailoverSymbolPriority(t *testing.T) {
+	now := time.Now()
+	a := newTestAggregator(ModeFailover, &now)
+
+	_, ok := a.aggregate("secondary", newMD("ETHUSD", "10"))
+	assert.True(t, ok)
+
+	_, ok = a.aggregate("primary", newMD("ETHUSD", "11"))
+	assert.False(t, ok)
+}
+
+func TestConsensusMedianAndOutliers(t *testing.T) {
+	now := time.Now()
+	a := newTestAggregator(ModeConsensus, &now)
+
+	_, ok := a.aggregate("a", newMD("BTCUSD", "100"))
+	assert.False(t, ok, "a single source is not enough")
+
+	md, ok := a.aggregate("b", newMD("BTCUSD", "102"))
+	assert.True(t, ok)
+	assert.Equal(t, "101", md.LastPrice.String())
+	assert.Equal(t, []string{"a", "b"}, md.Sources)
+
+	md, ok = a.aggregate("c", newMD("BTCUSD", "150"))
+	assert.True(t, ok)
+	assert.Equal(t, "101", md.LastPrice.String())
+	assert.Equal(t, []string{"a", "b"}, md.Sources)
+
+	md, ok = a.aggregate("d", newMD("BTCUSD", "101"))
+	assert.True(t, ok)
+	assert.Equal(t, "101", md.LastPrice.String())
+	assert.Equal(t, []string{"a", "b", "d"}, md.Sources)
+}
+
+func TestConsensusIgnoresStaleQuotes(t *testing.T) {
+	now := time.Now()
+	a := newTestAggregator(ModeConsensus, &now)
+
+	_, _ = a.aggregate("a", newMD("BTCUSD", "100"))
+	now = now.Add(2 * time.Minute)
+
+	_, ok := a.aggregate("b", newMD("BTCUSD", "102"))
+	assert.False(t, ok)
+}
+
+func TestConsensusDivergingQuotes(t *testing.T) {
+	now := time.Now()
+	a := newTestAggregator(ModeConsensus, &now)
+	a.minSources = 1
+
+	_, ok := a.aggregate("a", newMD("BTCUSD", "100"))
+	assert.True(t, ok)
+
+	// Ambos precios se desvían de la mediana: no hay consenso
+	_, ok = a.aggregate("b", newMD("BTCUSD", "200"))
+	assert.False(t, ok)
+}
+
+func TestNewAggregatorMinSources(t *testing.T) {
+	t.Setenv("MTZ_CRYPTO_AGGREGATOR_MIN_SOURCES", "0")
+	cfg := config.NewConfig(&flag.FlagSet{})
+
+	a := NewAggregator(cfg, zap.NewNop(), make(model.MdChannel)).(*aggregator)
+	assert.Equal(t, 1, a.minSources)
+}
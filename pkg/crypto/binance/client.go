@@ -0,0 +1,307 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/resilience"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// ProviderName nombre del proveedor para configuración y agregación
+const ProviderName = "binance"
+
+const (
+	channelTrade  = "trade"
+	channelTicker = "ticker"
+)
+
+type binanceClient struct {
+	config  *config.Config
+	logger  *zap.Logger
+	dialer  *websocket.Dialer
+	url     string
+	channel string
+	// symbolPairs pares por símbolo de Binance en mayúsculas
+	symbolPairs map[string]crypto.SymbolPair
+	mdChannel   model.MdChannel
+	reporter    crypto.Reporter
+	routines    lifecycle.Group
+}
+
+// binanceRequest mensaje de suscripción a streams
+type binanceRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int      `json:"id"`
+}
+
+// binanceEvent campos comunes a todos los eventos. Los mensajes de streams
+// combinados llegan envueltos en {"stream": ..., "data": ...}
+type binanceEvent struct {
+	EventType string          `json:"e"`
+	EventTime int64           `json:"E"`
+	Symbol    string          `json:"s"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type binanceTrade struct {
+	TradeID   int64               `json:"t"`
+	Price     decimal.NullDecimal `json:"p"`
+	TradeTime int64               `json:"T"`
+}
+
+type binanceTicker struct {
+	ClosePrice decimal.NullDecimal `json:"c"`
+	CloseTime  int64               `json:"C"`
+}
+
+func NewBinanceClient(
+	config *config.Config,
+	logger *zap.Logger,
+	dialer *websocket.Dialer,
+	mdChannel model.MdChannel,
+	reporter crypto.Reporter,
+) (crypto.Client, error) {
+	pairs, err := crypto.ProviderPairs(config, ProviderName)
+	if err != nil {
+		return nil, err
+	}
+
+	symbolPairs := map[string]crypto.SymbolPair{}
+	for _, pair := range pairs {
+		symbolPairs[strings.ToUpper(pair.ExternalSymbol)] = pair
+	}
+
+	channel := config.GetString("crypto.api.binance.channel")
+	if channel != channelTicker {
+		channel = channelTrade
+	}
+
+	return &binanceClient{
+		config:      config,
+		logger:      logger,
+		dialer:      dialer,
+		url:         config.GetString("crypto.api.binance.url"),
+		channel:     channel,
+		symbolPairs: symbolPairs,
+		mdChannel:   mdChannel,
+		reporter:    reporter,
+	}, nil
+}
+
+func (c *binanceClient) Symbols() []string {
+	symbols := make([]string, 0, len(c.symbolPairs))
+	for _, pair := range c.symbolPairs {
+		symbols = append(symbols, pair.Symbol)
+	}
+	sort.Strings(symbols)
+
+	return symbols
+}
+
+func (c *binanceClient) Start(ctx context.Context) error {
+	c.routines.Go(c.run)
+	c.logger.Info("binanceClient started")
+
+	return nil
+}
+
+// Stop cierra la conexión y detiene las reconexiones
+func (c *binanceClient) Stop(ctx context.Context) error {
+	err := c.routines.Stop(ctx)
+	c.logger.Info("binanceClient stopped")
+
+	return err
+}
+
+// run mantiene la conexión abierta, reconectando con backoff exponencial y jitter
+func (c *binanceClient) run(ctx context.Context) {
+	minDelay := c.config.GetDuration("crypto.api.binance.reconnect.min")
+	maxDelay := c.config.GetDuration("crypto.api.binance.reconnect.max")
+	attempt := 0
+
+	for {
+		received, err := c.consume(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			attempt = 0
+		}
+
+		c.reporter.Failure("", err)
+
+		delay := resilience.Backoff(minDelay, maxDelay, attempt)
+		attempt++
+
+		c.logger.Warn("websocket disconnected, reconnecting",
+			zap.Duration("delay", delay),
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+
+		if !lifecycle.Sleep(ctx, delay) {
+			return
+		}
+	}
+}
+
+// consume abre la conexión, se suscribe a los streams y publica cada tick en
+// el channel hasta que la conexión se cae. Indica si llegó a recibir mensajes.
+func (c *binanceClient) consume(ctx context.Context) (received bool, err error) {
+	conn, _, err := c.dialer.DialContext(ctx, c.url, nil)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	pingInterval := c.config.GetDuration("crypto.api.binance.ping.interval")
+	pongTimeout := c.config.GetDuration("crypto.api.binance.pong.timeout")
+
+	extendDeadline := func() {
+		_ = conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	}
+	extendDeadline()
+
+	conn.SetPongHandler(func(string) error {
+		extendDeadline()
+		return nil
+	})
+	conn.SetPingHandler(func(data string) error {
+		extendDeadline()
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(pongTimeout))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	if err := conn.WriteJSON(c.subscribeRequest()); err != nil {
+		return false, err
+	}
+
+	// Heartbeat propio, para detectar conexiones muertas del lado del servidor.
+	// Al detener el cliente se cierra la conexión para cortar la lectura.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pongTimeout)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return received, err
+		}
+		received = true
+		extendDeadline()
+
+		md, ok, err := c.parseMessage(msg)
+		if err != nil {
+			c.logger.Error("error parsing websocket message", zap.ByteString("msg", msg), zap.Error(err))
+			c.reporter.Failure(md.Symbol, err)
+			continue
+		}
+
+		if ok {
+			c.reporter.Success(md.Symbol)
+
+			select {
+			case c.mdChannel <- md:
+			case <-ctx.Done():
+				return received, ctx.Err()
+			}
+		}
+	}
+}
+
+func (c *binanceClient) subscribeRequest() binanceRequest {
+	params := make([]string, 0, len(c.symbolPairs))
+	for external := range c.symbolPairs {
+		params = append(params, fmt.Sprintf("%s@%s", strings.ToLower(external), c.channel))
+	}
+	sort.Strings(params)
+
+	return binanceRequest{Method: "SUBSCRIBE", Params: params, ID: 1}
+}
+
+// parseMessage convierte un evento de trade o ticker en market data. Los
+// mensajes que no son ticks (ej. respuesta a la suscripción) se ignoran.
+func (c *binanceClient) parseMessage(msg []byte) (md model.MarketData, ok bool, err error) {
+	var event binanceEvent
+	if err = json.Unmarshal(msg, &event); err != nil {
+		return md, false, err
+	}
+
+	if len(event.Data) > 0 {
+		msg = event.Data
+		event = binanceEvent{}
+		if err = json.Unmarshal(msg, &event); err != nil {
+			return md, false, err
+		}
+	}
+
+	pair, found := c.symbolPairs[event.Symbol]
+	if !found {
+		return md, false, nil
+	}
+
+	var price decimal.NullDecimal
+	var timestamp time.Time
+
+	switch event.EventType {
+	case "trade":
+		var trade binanceTrade
+		if err = json.Unmarshal(msg, &trade); err != nil {
+			return md, false, err
+		}
+		price = trade.Price
+		timestamp = time.UnixMilli(trade.TradeTime)
+	case "24hrTicker":
+		var ticker binanceTicker
+		if err = json.Unmarshal(msg, &ticker); err != nil {
+			return md, false, err
+		}
+		price = ticker.ClosePrice
+		timestamp = time.UnixMilli(event.EventTime)
+	default:
+		return md, false, nil
+	}
+
+	if !price.Valid {
+		return md, false, fmt.Errorf("last price not found")
+	}
+
+	md = model.MarketData{
+		Symbol:            pair.Symbol,
+		LastPrice:         pair.Round(price.Decimal),
+		LastPriceDateTime: timestamp,
+	}
+
+	return md, true, nil
+}
@@ -0,0 +1,135 @@
+package binance
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestServer inicia un servidor WebSocket que valida la suscripción y
+// envía los mensajes indicados, cerrando luego la conexión
+func newTestServer(t *testing.T, subscriptions *int32, msgs ...string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		var subscribe binanceRequest
+		assert.NoError(t, conn.ReadJSON(&subscribe))
+		assert.Equal(t, "SUBSCRIBE", subscribe.Method)
+		assert.Equal(t, []string{"btcusdt@trade", "ethusdt@trade"}, subscribe.Params)
+		atomic.AddInt32(subscriptions, 1)
+
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"result":null,"id":1}`)))
+		for _, msg := range msgs {
+			assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(msg)))
+		}
+	}))
+}
+
+func newTestClient(t *testing.T, serverURL string, mdChannel model.MdChannel) *binanceClient {
+	t.Setenv("MTZ_CRYPTO_API_BINANCE_URL", "ws"+strings.TrimPrefix(serverURL, "http"))
+	t.Setenv("MTZ_CRYPTO_API_BINANCE_PAIRS", "btcusdt;BTCUSD ethusdt;ETHUSD")
+	t.Setenv("MTZ_CRYPTO_API_BINANCE_PING_INTERVAL", "1s")
+	t.Setenv("MTZ_CRYPTO_API_BINANCE_PONG_TIMEOUT", "5s")
+	t.Setenv("MTZ_CRYPTO_API_BINANCE_RECONNECT_MIN", "10ms")
+	t.Setenv("MTZ_CRYPTO_API_BINANCE_RECONNECT_MAX", "50ms")
+
+	cfg := config.NewConfig(&flag.FlagSet{})
+
+	client, err := NewBinanceClient(cfg, zap.NewNop(), websocket.DefaultDialer, mdChannel, crypto.NopReporter{})
+	assert.NoError(t, err)
+
+	return client.(*binanceClient)
+}
+
+func TestConsumeTrades(t *testing.T) {
+	var subscriptions int32
+	server := newTestServer(t, &subscriptions,
+		`{"e":"trade","E":1628610304001,"s":"BTCUSDT","t":12345,"p":"45123.45","q":"0.1","T":1628610304000}`,
+		`{"stream":"ethusdt@trade","data":{"e":"trade","E":1628610305001,"s":"ETHUSDT","t":1,"p":"3012.5","q":"1","T":1628610305000}}`,
+	)
+	defer server.Close()
+
+	mdChannel := make(model.MdChannel)
+	client := newTestClient(t, server.URL, mdChannel)
+	assert.NoError(t, client.Start(context.Background()))
+
+	md := receive(t, mdChannel)
+	assert.Equal(t, "BTCUSD", md.Symbol)
+	assert.Equal(t, decimal.RequireFromString("45123.45"), md.LastPrice)
+	assert.Equal(t, int64(1_628_610_304_000), md.LastPriceDateTime.UnixMilli())
+
+	md = receive(t, mdChannel)
+	assert.Equal(t, "ETHUSD", md.Symbol)
+	assert.Equal(t, decimal.RequireFromString("3012.5"), md.LastPrice)
+
+	// Stop cierra la conexión abierta
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Stop(ctx))
+}
+
+func TestReconnectAndResubscribe(t *testing.T) {
+	var subscriptions int32
+	server := newTestServer(t, &subscriptions,
+		`{"e":"trade","E":1628610304001,"s":"BTCUSDT","t":12345,"p":"45123.45","q":"0.1","T":1628610304000}`,
+	)
+	defer server.Close()
+
+	mdChannel := make(model.MdChannel)
+	client := newTestClient(t, server.URL, mdChannel)
+	assert.NoError(t, client.Start(context.Background()))
+
+	// El servidor cierra la conexión luego de cada mensaje: el cliente debe
+	// reconectarse y volver a suscribirse
+	receive(t, mdChannel)
+	receive(t, mdChannel)
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&subscriptions), int32(2))
+	assert.NoError(t, client.Stop(context.Background()))
+}
+
+func TestParseTicker(t *testing.T) {
+	client := &binanceClient{symbolPairs: map[string]crypto.SymbolPair{
+		"BTCUSDT": {Symbol: "BTCUSD", ExternalSymbol: "btcusdt"},
+	}}
+
+	md, ok, err := client.parseMessage([]byte(
+		`{"e":"24hrTicker","E":1628610304000,"s":"BTCUSDT","p":"-10.0","P":"-0.02","c":"45000.1","C":1628610303999}`))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, decimal.RequireFromString("45000.1"), md.LastPrice)
+	assert.Equal(t, int64(1_628_610_304_000), md.LastPriceDateTime.UnixMilli())
+
+	_, ok, err = client.parseMessage([]byte(`{"e":"trade","s":"XRPUSDT","p":"1.0","T":1}`))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func receive(t *testing.T, mdChannel model.MdChannel) model.MarketData {
+	select {
+	case md := <-mdChannel:
+		return md
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for market data")
+	}
+
+	return model.MarketData{}
+}
@@ -0,0 +1,207 @@
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ModeRecord graba cada request y response en el cassette
+	ModeRecord = "record"
+	// ModeReplay responde con las interacciones grabadas en el cassette
+	ModeReplay = "replay"
+)
+
+// Interaction request HTTP y su resultado, tal como quedan grabados en el
+// cassette (un JSON por línea)
+type Interaction struct {
+	Request    Request   `json:"request"`
+	Response   Response  `json:"response"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type Response struct {
+	StatusCode int         `json:"statusCode,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	// Error error de transporte (timeout, conexión rechazada, etc.)
+	Error string `json:"error,omitempty"`
+}
+
+// Recorder http.RoundTripper que delega en otro y graba cada interacción
+type Recorder struct {
+	next http.RoundTripper
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder abre (o crea) el cassette y agrega al final las interacciones nuevas
+func NewRecorder(path string, next http.RoundTripper) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Recorder{next: next, file: file}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	interaction := Interaction{
+		Request:    Request{Method: req.Method, URL: req.URL.String()},
+		RecordedAt: time.Now().UTC(),
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		interaction.Response.Error = err.Error()
+		return resp, r.write(interaction, err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		interaction.Response.Error = err.Error()
+		return nil, r.write(interaction, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction.Response.StatusCode = resp.StatusCode
+	interaction.Response.Header = resp.Header
+	interaction.Response.Body = string(body)
+
+	return resp, r.write(interaction, nil)
+}
+
+// write agrega la interacción al cassette. Un error de escritura no oculta el
+// error original del request.
+func (r *Recorder) write(interaction Interaction, reqErr error) error {
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.file.Write(append(line, '\n')); err != nil && reqErr == nil {
+		return fmt.Errorf("error writing cassette: %w", err)
+	}
+
+	return reqErr
+}
+
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+// Replayer http.RoundTripper que responde con las interacciones de un cassette.
+// Las interacciones de un mismo request se devuelven en el orden grabado; al
+// agotarse se repite la última.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+}
+
+func NewReplayer(path string) (*Replayer, error) {
+	interactions, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	byRequest := make(map[string][]Interaction)
+	for _, i := range interactions {
+		key := requestKey(i.Request.Method, i.Request.URL)
+		byRequest[key] = append(byRequest[key], i)
+	}
+
+	return &Replayer{interactions: byRequest}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := requestKey(req.Method, req.URL.String())
+
+	r.mu.Lock()
+	queue := r.interactions[key]
+	if len(queue) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("no recorded interaction for %s", key)
+	}
+	interaction := queue[0]
+	if len(queue) > 1 {
+		r.interactions[key] = queue[1:]
+	}
+	r.mu.Unlock()
+
+	if interaction.Response.Error != "" {
+		return nil, errors.New(interaction.Response.Error)
+	}
+
+	header := interaction.Response.Header
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Load lee todas las interacciones de un cassette
+func Load(path string) (interactions []Interaction, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+
+	return interactions, scanner.Err()
+}
+
+func requestKey(method, url string) string {
+	return method + " " + url
+}
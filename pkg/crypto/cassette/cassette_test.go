@@ -0,0 +1,65 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.Header().Add("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "test.cassette.jsonl")
+	recorder, err := NewRecorder(path, server.Client().Transport)
+	assert.NoError(t, err)
+
+	client := &http.Client{Transport: recorder}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/ticker/btc-usd")
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.NoError(t, recorder.Close())
+
+	interactions, err := Load(path)
+	assert.NoError(t, err)
+	assert.Len(t, interactions, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, interactions[0].Response.StatusCode)
+	assert.Equal(t, `{"success":true}`, interactions[1].Response.Body)
+
+	// El replay no consulta al servidor
+	server.Close()
+	replayer, err := NewReplayer(path)
+	assert.NoError(t, err)
+	client = &http.Client{Transport: replayer}
+
+	expected := []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK}
+	for _, status := range expected {
+		resp, err := client.Get(server.URL + "/ticker/btc-usd")
+		assert.NoError(t, err)
+		assert.Equal(t, status, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if status == http.StatusOK {
+			assert.Equal(t, `{"success":true}`, string(body))
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		}
+	}
+
+	_, err = client.Get(server.URL + "/ticker/eth-usd")
+	assert.Error(t, err)
+}
@@ -0,0 +1,306 @@
+package coingecko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/resilience"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// ProviderName nombre del proveedor para configuración y agregación
+const ProviderName = "coingecko"
+
+var errRateLimited = errors.New("rate limited by CoinGecko API")
+
+type coingeckoClient struct {
+	config      *config.Config
+	logger      *zap.Logger
+	httpClient  *http.Client
+	symbolPairs []coingeckoSymbolPair
+	baseURL     string
+	batchSize   int
+	mdChannel   model.MdChannel
+	reporter    crypto.Reporter
+	retry       resilience.RetryPolicy
+	breaker     *resilience.CircuitBreaker
+	routines    lifecycle.Group
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+// coingeckoSymbolPair relaciona un id de CoinGecko y la moneda de cotización
+// con el símbolo interno
+type coingeckoSymbolPair struct {
+	crypto.SymbolPair
+	ID         string
+	VsCurrency string
+}
+
+// coingeckoResponse precios por id y moneda de cotización, más el campo last_updated_at
+type coingeckoResponse map[string]map[string]decimal.NullDecimal
+
+func NewCoingeckoClient(
+	config *config.Config,
+	logger *zap.Logger,
+	httpClient *http.Client,
+	mdChannel model.MdChannel,
+	reporter crypto.Reporter,
+) (crypto.Client, error) {
+	baseURL := config.GetString("crypto.api.coingecko.url")
+
+	pairs, err := crypto.ProviderPairs(config, ProviderName)
+	if err != nil {
+		return nil, err
+	}
+
+	symbolPairs := make([]coingeckoSymbolPair, 0, len(pairs))
+	for _, pair := range pairs {
+		symbolPair, err := parseSymbolPair(pair)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CoinGecko pair %s: %w", pair.Symbol, err)
+		}
+		symbolPairs = append(symbolPairs, symbolPair)
+	}
+
+	batchSize := config.GetInt("crypto.api.coingecko.batch.size")
+	if batchSize <= 0 {
+		batchSize = len(symbolPairs)
+	}
+
+	return &coingeckoClient{
+		config:      config,
+		logger:      logger,
+		httpClient:  httpClient,
+		baseURL:     baseURL,
+		symbolPairs: symbolPairs,
+		batchSize:   batchSize,
+		mdChannel:   mdChannel,
+		reporter:    reporter,
+		retry:       crypto.ProviderRetryPolicy(config, ProviderName),
+		breaker:     crypto.ProviderCircuitBreaker(config, ProviderName),
+	}, nil
+}
+
+// parseSymbolPair interpreta el símbolo externo con el formato 'id/moneda'
+func parseSymbolPair(pair crypto.SymbolPair) (rs coingeckoSymbolPair, err error) {
+	external := strings.Split(pair.ExternalSymbol, "/")
+	if len(external) != 2 || external[0] == "" || external[1] == "" {
+		return rs, fmt.Errorf("expected external symbol 'id/currency'")
+	}
+
+	return coingeckoSymbolPair{
+		SymbolPair: pair,
+		ID:         external[0],
+		VsCurrency: strings.ToLower(external[1]),
+	}, nil
+}
+
+func (c *coingeckoClient) Symbols() []string {
+	symbols := make([]string, 0, len(c.symbolPairs))
+	for _, pair := range c.symbolPairs {
+		symbols = append(symbols, pair.Symbol)
+	}
+
+	return symbols
+}
+
+func (c *coingeckoClient) Start(ctx context.Context) error {
+	interval := c.config.GetDuration("crypto.api.coingecko.poll.interval")
+
+	// Actualización inicial y periódica de la market data
+	c.routines.Go(func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			c.updateMarketData(ctx)
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	c.logger.Info("coingeckoClient started")
+
+	return nil
+}
+
+func (c *coingeckoClient) Stop(ctx context.Context) error {
+	err := c.routines.Stop(ctx)
+	c.logger.Info("coingeckoClient stopped")
+
+	return err
+}
+
+// updateMarketData Obtiene la MD de todos los activos en lotes y la publica en el channel
+func (c *coingeckoClient) updateMarketData(ctx context.Context) {
+	for start := 0; start < len(c.symbolPairs); start += c.batchSize {
+		end := start + c.batchSize
+		if end > len(c.symbolPairs) {
+			end = len(c.symbolPairs)
+		}
+		batch := c.symbolPairs[start:end]
+
+		c.logger.Debug("requesting MD batch", zap.Int("size", len(batch)))
+
+		var mds []model.MarketData
+		err := resilience.Retry(ctx, c.retry, func() error {
+			return c.breaker.Do(func() (err error) {
+				mds, err = c.retrieveMD(ctx, batch)
+				return err
+			})
+		})
+		if err != nil {
+			c.logger.Error("error requesting MD batch",
+				zap.Int("size", len(batch)),
+				zap.String("breaker", c.breaker.State()),
+				zap.Error(err))
+			for _, pair := range batch {
+				c.reporter.Failure(pair.Symbol, err)
+			}
+			if errors.Is(err, errRateLimited) || errors.Is(err, resilience.ErrCircuitOpen) {
+				return
+			}
+			continue
+		}
+
+		for _, md := range mds {
+			c.reporter.Success(md.Symbol)
+
+			select {
+			case c.mdChannel <- md:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// retrieveMD Obtiene la Market data de un lote de activos en un único request.
+// El rate limit, los 4xx y la cancelación del contexto son errores permanentes:
+// no se reintentan.
+func (c *coingeckoClient) retrieveMD(ctx context.Context, batch []coingeckoSymbolPair) (mds []model.MarketData, err error) {
+	if until := c.rateLimitedUntil(); time.Now().Before(until) {
+		return mds, resilience.Throttled(fmt.Errorf("%w until %s", errRateLimited, until.Format(time.RFC3339)))
+	}
+
+	ids := map[string]bool{}
+	currencies := map[string]bool{}
+	for _, pair := range batch {
+		ids[pair.ID] = true
+		currencies[pair.VsCurrency] = true
+	}
+
+	query := url.Values{}
+	query.Set("ids", joinKeys(ids))
+	query.Set("vs_currencies", joinKeys(currencies))
+	query.Set("include_last_updated_at", "true")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/simple/price?%s", c.baseURL, query.Encode()), nil)
+	if err != nil {
+		return mds, resilience.Permanent(err)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return mds, resilience.Permanent(err)
+		}
+		return mds, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode == http.StatusTooManyRequests {
+		until := time.Now().Add(resilience.RetryAfter(httpResp.Header.Get("Retry-After")))
+		c.setRateLimitedUntil(until)
+		return mds, resilience.Throttled(fmt.Errorf("%w until %s", errRateLimited, until.Format(time.RFC3339)))
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("invalid HTTP status code: %d", httpResp.StatusCode)
+		if httpResp.StatusCode >= 400 && httpResp.StatusCode < 500 {
+			err = resilience.Permanent(err)
+		}
+		return mds, err
+	}
+
+	var resp coingeckoResponse
+	err = json.NewDecoder(httpResp.Body).Decode(&resp)
+	if err != nil {
+		return mds, err
+	}
+
+	for _, pair := range batch {
+		prices, found := resp[pair.ID]
+		if !found {
+			c.logger.Warn("id not found in CoinGecko response", zap.String("id", pair.ID))
+			c.reporter.Failure(pair.Symbol, fmt.Errorf("id %s not found", pair.ID))
+			continue
+		}
+
+		price := prices[pair.VsCurrency]
+		if !price.Valid {
+			c.logger.Warn("last price not found",
+				zap.String("id", pair.ID),
+				zap.String("vsCurrency", pair.VsCurrency))
+			c.reporter.Failure(pair.Symbol, fmt.Errorf("last price not found"))
+			continue
+		}
+
+		timestamp := time.Now()
+		if updatedAt := prices["last_updated_at"]; updatedAt.Valid {
+			timestamp = time.Unix(updatedAt.Decimal.IntPart(), 0)
+		}
+
+		mds = append(mds, model.MarketData{
+			Symbol:            pair.Symbol,
+			LastPrice:         pair.Round(price.Decimal),
+			LastPriceDateTime: timestamp,
+		})
+	}
+
+	return mds, nil
+}
+
+func (c *coingeckoClient) rateLimitedUntil() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.blockedUntil
+}
+
+func (c *coingeckoClient) setRateLimitedUntil(until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.blockedUntil = until
+}
+
+func joinKeys(set map[string]bool) string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return strings.Join(keys, ",")
+}
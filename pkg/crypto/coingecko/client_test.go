@@ -0,0 +1,99 @@
+package coingecko
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestUpdateMarketDataBatch(t *testing.T) {
+	requests := 0
+
+	// start test server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+
+		// Validamos que se pidan todos los ids en un único request
+		assert.Equal(t, "/simple/price", req.URL.Path)
+		assert.Equal(t, "bitcoin,ethereum", req.URL.Query().Get("ids"))
+		assert.Equal(t, "usd", req.URL.Query().Get("vs_currencies"))
+
+		rw.Header().Add("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write([]byte(`{
+			"bitcoin": {"usd": 45123.45, "last_updated_at": 1628610304},
+			"ethereum": {"usd": 3012.5, "last_updated_at": 1628610305}
+		}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	t.Setenv("MTZ_CRYPTO_API_COINGECKO_URL", server.URL)
+	t.Setenv("MTZ_CRYPTO_API_COINGECKO_PAIRS", "bitcoin/usd;BTCUSD ethereum/usd;ETHUSD")
+	t.Setenv("MTZ_CRYPTO_API_COINGECKO_BATCH_SIZE", "10")
+	t.Setenv("MTZ_CRYPTO_API_COINGECKO_POLL_INTERVAL", "1m")
+
+	cfg := config.NewConfig(&flag.FlagSet{})
+	mdChannel := make(chan model.MarketData)
+	client, err := NewCoingeckoClient(cfg, zap.NewNop(), server.Client(), mdChannel, crypto.NopReporter{})
+	assert.NoError(t, err)
+	assert.NoError(t, client.Start(context.Background()))
+
+	md := <-mdChannel
+	assert.Equal(t, "BTCUSD", md.Symbol)
+	assert.Equal(t, decimal.RequireFromString("45123.45"), md.LastPrice)
+	assert.Equal(t, int64(1_628_610_304), md.LastPriceDateTime.Unix())
+
+	md = <-mdChannel
+	assert.Equal(t, "ETHUSD", md.Symbol)
+	assert.Equal(t, decimal.RequireFromString("3012.5"), md.LastPrice)
+	assert.Equal(t, 1, requests)
+
+	assert.NoError(t, client.Stop(context.Background()))
+}
+
+func TestRetrieveMDRateLimited(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.Header().Add("Retry-After", "120")
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	t.Setenv("MTZ_CRYPTO_API_COINGECKO_URL", server.URL)
+	t.Setenv("MTZ_CRYPTO_API_COINGECKO_PAIRS", "bitcoin/usd;BTCUSD")
+
+	cfg := config.NewConfig(&flag.FlagSet{})
+	c, err := NewCoingeckoClient(cfg, zap.NewNop(), server.Client(), make(chan model.MarketData), crypto.NopReporter{})
+	assert.NoError(t, err)
+	client := c.(*coingeckoClient)
+
+	_, err = client.retrieveMD(context.Background(), client.symbolPairs)
+	assert.ErrorIs(t, err, errRateLimited)
+
+	// Mientras dure el Retry-After no se vuelve a consultar la API
+	_, err = client.retrieveMD(context.Background(), client.symbolPairs)
+	assert.ErrorIs(t, err, errRateLimited)
+	assert.Equal(t, 1, requests)
+}
+
+func TestParseSymbolPair(t *testing.T) {
+	symbolPair := crypto.SymbolPair{Symbol: "USDCUSD", ExternalSymbol: "usd-coin/USD"}
+	pair, err := parseSymbolPair(symbolPair)
+	assert.NoError(t, err)
+	assert.Equal(t, coingeckoSymbolPair{SymbolPair: symbolPair, ID: "usd-coin", VsCurrency: "usd"}, pair)
+
+	_, err = parseSymbolPair(crypto.SymbolPair{Symbol: "BTCUSD", ExternalSymbol: "bitcoin"})
+	assert.Error(t, err)
+}
@@ -1,5 +1,88 @@
 package crypto
 
+import (
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/health"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+)
+
+// Client proveedor de market data. Stop detiene las consultas; luego de Stop
+// no se publica en el channel.
 type Client interface {
-	Start()
+	lifecycle.Component
+	// Symbols símbolos internos configurados en el proveedor
+	Symbols() []string
+}
+
+// SymbolPair relación entre el símbolo interno y el símbolo del proveedor,
+// con sus opciones. Los valores cero usan los defaults del proveedor.
+type SymbolPair struct {
+	Symbol         string
+	ExternalSymbol string
+	Provider       string
+	PollInterval   time.Duration
+	// Decimals decimales a los que se redondea el precio (nil para no redondear)
+	Decimals     *int32
+	MaxStaleness time.Duration
+}
+
+// Subscriber proveedor que permite cambiar los símbolos consultados sin reiniciar
+type Subscriber interface {
+	Client
+	// Pairs devuelve los pares consultados actualmente
+	Pairs() []SymbolPair
+	// SetPairs reemplaza los pares consultados a partir del próximo ciclo
+	SetPairs(pairs []SymbolPair)
+}
+
+// Reporter recibe el resultado de las consultas de un proveedor. Los errores
+// que no corresponden a un símbolo en particular se informan con symbol vacío.
+type Reporter interface {
+	Success(symbol string)
+	Failure(symbol string, err error)
+}
+
+// NopReporter descarta los resultados
+type NopReporter struct{}
+
+func (NopReporter) Success(symbol string)            {}
+func (NopReporter) Failure(symbol string, err error) {}
+
+type healthReporter struct {
+	component *health.Component
+}
+
+// NewHealthReporter informa el resultado de las consultas al registro de salud
+func NewHealthReporter(component *health.Component) Reporter {
+	return &healthReporter{component: component}
+}
+
+func (r *healthReporter) Success(symbol string) {
+	r.component.Success()
+}
+
+func (r *healthReporter) Failure(symbol string, err error) {
+	r.component.Failure(err)
+}
+
+type multiReporter struct {
+	reporters []Reporter
+}
+
+// NewMultiReporter informa los resultados a todos los reporters indicados
+func NewMultiReporter(reporters ...Reporter) Reporter {
+	return &multiReporter{reporters: reporters}
+}
+
+func (r *multiReporter) Success(symbol string) {
+	for _, reporter := range r.reporters {
+		reporter.Success(symbol)
+	}
+}
+
+func (r *multiReporter) Failure(symbol string, err error) {
+	for _, reporter := range r.reporters {
+		reporter.Failure(symbol, err)
+	}
 }
@@ -1,33 +1,38 @@
 package cryptonator
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"strings"
-	"sync"
 	"time"
 
 	"github.com/matbarofex/mtz-crypto/pkg/config"
 	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
 	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/resilience"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
-type cryptonatorClient struct {
-	config      *config.Config
-	logger      *zap.Logger
-	httpClient  *http.Client
-	symbolPairs []cryptonatorSymbolPair
-	baseURL     string
-	mdChannel   model.MdChannel
-}
+// ProviderName nombre del proveedor para configuración y agregación
+const ProviderName = "cryptonator"
 
-type cryptonatorSymbolPair struct {
-	Symbol         string
-	ExternalSymbol string
+var errRateLimited = errors.New("rate limited by Cryptonator API")
+
+type cryptonatorClient struct {
+	config     *config.Config
+	logger     *zap.Logger
+	httpClient *http.Client
+	baseURL    string
+	mdChannel  model.MdChannel
+	reporter   crypto.Reporter
+	retry      resilience.RetryPolicy
+	breaker    *resilience.CircuitBreaker
+	scheduler  *crypto.Scheduler
+	routines   lifecycle.Group
 }
 
 type cryptonatorTicker struct {
@@ -48,133 +53,204 @@ func NewCryptonatorClient(
 	logger *zap.Logger,
 	httpClient *http.Client,
 	mdChannel model.MdChannel,
-) crypto.Client {
+	reporter crypto.Reporter,
+	demand crypto.Demand,
+) (crypto.Client, error) {
 	baseURL := config.GetString("crypto.api.cryptonator.url")
-	symbolPairsStringSlice := config.GetStringSlice("crypto.api.cryptonator.pairs")
-
-	symbolPairs := []cryptonatorSymbolPair{}
-	for _, pairStr := range symbolPairsStringSlice {
-		pairs := strings.Split(pairStr, ";")
-		symbolPairs = append(symbolPairs, cryptonatorSymbolPair{
-			ExternalSymbol: pairs[0],
-			Symbol:         pairs[1],
-		})
+
+	symbolPairs, err := crypto.ProviderPairs(config, ProviderName)
+	if err != nil {
+		return nil, err
 	}
 
+	scheduler := crypto.NewScheduler(
+		config.GetDuration("crypto.api.cryptonator.poll.interval"),
+		config.GetDuration("crypto.api.cryptonator.poll.hot.interval"),
+		config.GetInt("crypto.api.cryptonator.rate.limit"),
+		config.GetDuration("crypto.api.cryptonator.rate.window"),
+		demand,
+	)
+	scheduler.SetPairs(symbolPairs)
+
 	return &cryptonatorClient{
-		config:      config,
-		logger:      logger,
-		httpClient:  httpClient,
-		baseURL:     baseURL,
-		symbolPairs: symbolPairs,
-		mdChannel:   mdChannel,
-	}
+		config:     config,
+		logger:     logger,
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		mdChannel:  mdChannel,
+		reporter:   reporter,
+		retry:      crypto.ProviderRetryPolicy(config, ProviderName),
+		breaker:    crypto.ProviderCircuitBreaker(config, ProviderName),
+		scheduler:  scheduler,
+	}, nil
 }
 
-func (c *cryptonatorClient) Start() {
-	// Actualización inicial
-	c.updateMarketData()
-	c.logger.Info("cryptonatorClient started")
+func (c *cryptonatorClient) Symbols() []string {
+	pairs := c.Pairs()
 
-	// Actualización periódica de la market data
-	interval := c.config.GetDuration("crypto.api.cryptonator.poll.interval")
-	ticker := time.NewTicker(interval)
-	workers := c.config.GetInt("crypto.api.cryptonator.workers")
+	symbols := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		symbols = append(symbols, pair.Symbol)
+	}
 
-	go func() {
-		for range ticker.C {
-			c.updateMarketDataWithWorkers(workers)
-		}
-	}()
+	return symbols
 }
 
-// updateMarketData Obtiene la MD de todos los activos y la publica en el channel
-func (c *cryptonatorClient) updateMarketData() {
-	wg := sync.WaitGroup{}
-	wg.Add(len(c.symbolPairs))
-
-	for _, p := range c.symbolPairs {
-		go func(pair cryptonatorSymbolPair) {
-			c.logger.Debug("requesting MD", zap.String("externalSymbol", pair.ExternalSymbol))
+func (c *cryptonatorClient) Pairs() []crypto.SymbolPair {
+	return c.scheduler.Pairs()
+}
 
-			md, err := c.retrieveMD(pair.ExternalSymbol, pair.Symbol)
-			if err != nil {
-				c.logger.Error("error requesting MD",
-					zap.String("externalSymbol", pair.ExternalSymbol),
-					zap.Error(err))
-			}
+// SetPairs reemplaza los pares; los nuevos se consultan en el próximo turno libre
+func (c *cryptonatorClient) SetPairs(pairs []crypto.SymbolPair) {
+	c.scheduler.SetPairs(pairs)
+	c.logger.Info("cryptonator pairs updated", zap.Int("pairs", len(pairs)))
+}
 
-			c.mdChannel <- md
-			wg.Done()
-		}(p)
+// Start inicia la consulta periódica de la market data. El scheduler decide qué
+// par consultar y cuándo; los workers limitan la concurrencia.
+func (c *cryptonatorClient) Start(ctx context.Context) error {
+	workers := c.config.GetInt("crypto.api.cryptonator.workers")
+	if workers < 1 {
+		workers = 1
 	}
 
-	wg.Wait()
-}
-
-// updateMarketDataWithWorkers Obtiene la MD limitando la concurrencia a una cantidad de workers
-func (c *cryptonatorClient) updateMarketDataWithWorkers(workers int) {
-	ch := make(chan cryptonatorSymbolPair)
-	wg := sync.WaitGroup{}
-	wg.Add(workers)
+	ch := make(chan crypto.SymbolPair)
 	for i := 0; i < workers; i++ {
-		go func(workerID int) {
+		workerID := i
+		c.routines.Go(func(ctx context.Context) {
 			for pair := range ch {
 				c.logger.Debug("requesting MD",
 					zap.Int("worker", workerID),
 					zap.String("externalSymbol", pair.ExternalSymbol))
+				c.poll(ctx, pair)
+			}
+		})
+	}
 
-				md, err := c.retrieveMD(pair.ExternalSymbol, pair.Symbol)
-				if err != nil {
-					c.logger.Error("error requesting MD",
-						zap.String("externalSymbol", pair.ExternalSymbol),
-						zap.Error(err))
-				}
+	c.routines.Go(func(ctx context.Context) {
+		defer close(ch)
 
-				c.logger.Debug("sending MD to channel",
-					zap.Int("worker", workerID),
-					zap.Any("md", md))
+		for {
+			pair, wait, ok := c.scheduler.Next()
+			if !ok {
+				if !lifecycle.Sleep(ctx, wait) {
+					return
+				}
+				continue
+			}
 
-				c.mdChannel <- md
+			select {
+			case ch <- pair:
+			case <-ctx.Done():
+				return
 			}
+		}
+	})
+
+	c.logger.Info("cryptonatorClient started", zap.Int("workers", workers))
 
-			wg.Done()
-		}(i)
+	return nil
+}
+
+// Stop detiene el scheduler y espera a que terminen las consultas en curso
+func (c *cryptonatorClient) Stop(ctx context.Context) error {
+	err := c.routines.Stop(ctx)
+	c.logger.Info("cryptonatorClient stopped")
+
+	return err
+}
+
+// poll obtiene la MD de un par y la publica en el channel
+func (c *cryptonatorClient) poll(ctx context.Context, pair crypto.SymbolPair) {
+	md, err := c.fetchMD(ctx, pair)
+	if err != nil {
+		return
+	}
+
+	select {
+	case c.mdChannel <- md:
+	case <-ctx.Done():
 	}
+}
+
+// fetchMD obtiene la MD de un par con reintentos, a través del circuit breaker
+// del proveedor. Ante un error lo reporta y no se publica ningún precio.
+func (c *cryptonatorClient) fetchMD(ctx context.Context, pair crypto.SymbolPair) (md model.MarketData, err error) {
+	attempt := 0
+	err = resilience.Retry(ctx, c.retry, func() error {
+		// Los reintentos también consumen el presupuesto de requests
+		if attempt > 0 && !lifecycle.Sleep(ctx, c.scheduler.Wait()) {
+			return resilience.Permanent(ctx.Err())
+		}
+		attempt++
 
-	for _, p := range c.symbolPairs {
-		ch <- p
+		return c.breaker.Do(func() (err error) {
+			md, err = c.retrieveMD(ctx, pair.ExternalSymbol, pair.Symbol)
+			return err
+		})
+	})
+	if err != nil {
+		c.logger.Error("error requesting MD",
+			zap.String("externalSymbol", pair.ExternalSymbol),
+			zap.String("breaker", c.breaker.State()),
+			zap.Error(err))
+		c.reporter.Failure(pair.Symbol, err)
+		return md, err
 	}
 
-	close(ch)
-	wg.Wait()
+	md.LastPrice = pair.Round(md.LastPrice)
+	c.reporter.Success(pair.Symbol)
+
+	return md, nil
 }
 
-// retrieveMD Obtiene la Market data de un activo
-func (c *cryptonatorClient) retrieveMD(externalSymbol, symbol string) (md model.MarketData, err error) {
-	httpResp, err := c.httpClient.Get(fmt.Sprintf("%s/ticker/%s", c.baseURL, externalSymbol))
+// retrieveMD Obtiene la Market data de un activo. Los errores que no se
+// resuelven reintentando (símbolo inexistente, 4xx, rate limit) son permanentes,
+// al igual que la cancelación del contexto al detener el cliente.
+func (c *cryptonatorClient) retrieveMD(ctx context.Context, externalSymbol, symbol string) (md model.MarketData, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/ticker/%s", c.baseURL, externalSymbol), nil)
 	if err != nil {
+		return md, resilience.Permanent(err)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return md, resilience.Permanent(err)
+		}
 		return md, err
 	}
 
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode == http.StatusTooManyRequests {
+		until := time.Now().Add(resilience.RetryAfter(httpResp.Header.Get("Retry-After")))
+		c.scheduler.Block(until)
+		return md, resilience.Throttled(fmt.Errorf("%w until %s", errRateLimited, until.Format(time.RFC3339)))
+	}
+
 	if httpResp.StatusCode != http.StatusOK {
-		return md, fmt.Errorf("invalid HTTP status code: %d", httpResp.StatusCode)
+		err = fmt.Errorf("invalid HTTP status code: %d", httpResp.StatusCode)
+		if httpResp.StatusCode >= 400 && httpResp.StatusCode < 500 {
+			err = resilience.Permanent(err)
+		}
+		return md, err
 	}
 
 	var resp cryptonatorResponse
 
-	defer httpResp.Body.Close()
 	err = json.NewDecoder(httpResp.Body).Decode(&resp)
 	if err != nil {
 		return md, err
 	}
 
 	if !resp.Success {
-		return md, errors.New(resp.Error)
+		return md, resilience.Permanent(errors.New(resp.Error))
 	}
 
 	if !resp.Ticker.Price.Valid {
-		return md, fmt.Errorf("last price not found")
+		return md, resilience.Permanent(fmt.Errorf("last price not found"))
 	}
 
 	timestamp := time.Unix(int64(resp.Timestamp), 0)
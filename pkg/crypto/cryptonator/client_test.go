@@ -1,22 +1,24 @@
 package cryptonator
 
 import (
+	"context"
 	"flag"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto/cassette"
 	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/resilience"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
 
-func (c *cryptonatorClient) TestUpdateMarketData() {
-	c.updateMarketData()
-}
-
 func TestUpdateMarketData(t *testing.T) {
 	// start test server
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -45,11 +47,214 @@ func TestUpdateMarketData(t *testing.T) {
 	cfg := config.NewConfig(&flag.FlagSet{})
 	logger := zap.NewNop()
 	mdChannel := make(chan model.MarketData)
-	client := NewCryptonatorClient(cfg, logger, server.Client(), mdChannel)
-	go client.(*cryptonatorClient).updateMarketData()
+	client, err := NewCryptonatorClient(cfg, logger, server.Client(), mdChannel, crypto.NopReporter{}, crypto.NopDemand{})
+	assert.NoError(t, err)
+	c := client.(*cryptonatorClient)
+	go c.poll(context.Background(), c.Pairs()[0])
 
 	md := <-mdChannel
 	assert.Equal(t, "SYMBOL1", md.Symbol)
 	assert.Equal(t, decimal.RequireFromString("123.456"), md.LastPrice)
 	assert.Equal(t, int64(1_628_610_304), md.LastPriceDateTime.Unix())
 }
+
+func TestRetrieveMDRecordedEdgeCases(t *testing.T) {
+	replayer, err := cassette.NewReplayer("testdata/edge_cases.cassette.jsonl")
+	assert.NoError(t, err)
+
+	client := &cryptonatorClient{
+		logger:     zap.NewNop(),
+		httpClient: &http.Client{Transport: replayer},
+		baseURL:    "https://api.cryptonator.com/api",
+	}
+
+	md, err := client.retrieveMD(context.Background(), "btc-usd", "BTCUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, "BTCUSD", md.Symbol)
+	assert.Equal(t, decimal.RequireFromString("45123.45"), md.LastPrice)
+
+	_, err = client.retrieveMD(context.Background(), "eth-usd", "ETHUSD")
+	assert.EqualError(t, err, "Pair not found")
+
+	_, err = client.retrieveMD(context.Background(), "ada-usd", "ADAUSD")
+	assert.EqualError(t, err, "last price not found")
+
+	_, err = client.retrieveMD(context.Background(), "dot-usd", "DOTUSD")
+	assert.EqualError(t, err, "invalid HTTP status code: 502")
+
+	_, err = client.retrieveMD(context.Background(), "xrp-usd", "XRPUSD")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Client.Timeout exceeded")
+	}
+}
+
+func TestSetPairsAppliesOnNextCycle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write([]byte(`{"ticker":{"price":"1.5"},"timestamp":1628610304,"success":true,"error":""}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_URL", server.URL)
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_PAIRS", "btc-usd;BTCUSD")
+
+	cfg := config.NewConfig(&flag.FlagSet{})
+	mdChannel := make(chan model.MarketData, 10)
+	c, err := NewCryptonatorClient(cfg, zap.NewNop(), server.Client(), mdChannel, crypto.NopReporter{}, crypto.NopDemand{})
+	assert.NoError(t, err)
+	client := c.(*cryptonatorClient)
+	assert.Equal(t, []string{"BTCUSD"}, client.Symbols())
+
+	client.SetPairs([]crypto.SymbolPair{{Symbol: "ETHUSD", ExternalSymbol: "eth-usd"}})
+	pair, _, ok := client.scheduler.Next()
+	assert.True(t, ok)
+	client.poll(context.Background(), pair)
+
+	md := <-mdChannel
+	assert.Equal(t, "ETHUSD", md.Symbol)
+	assert.Len(t, mdChannel, 0)
+	assert.Equal(t, []string{"ETHUSD"}, client.Symbols())
+}
+
+func TestNewCryptonatorClientInvalidPairs(t *testing.T) {
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_PAIRS", "btc-usd")
+
+	cfg := config.NewConfig(&flag.FlagSet{})
+	_, err := NewCryptonatorClient(cfg, zap.NewNop(), http.DefaultClient, make(model.MdChannel), crypto.NopReporter{}, crypto.NopDemand{})
+	assert.EqualError(t, err, `invalid cryptonator pairs: "btc-usd": expected 'externalSymbol;SYMBOL'`)
+}
+
+func TestUpdateMarketDataRetriesAndOpensBreaker(t *testing.T) {
+	var requests int32
+	failing := int32(2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= atomic.LoadInt32(&failing) {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err := rw.Write([]byte(`{"ticker":{"price":"1.5"},"timestamp":1628610304,"success":true,"error":""}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_URL", server.URL)
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_PAIRS", "btc-usd;BTCUSD")
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_RETRY_ATTEMPTS", "3")
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_RETRY_MIN", "1ms")
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_RETRY_MAX", "2ms")
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_BREAKER_FAILURES", "3")
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_BREAKER_OPEN_TIMEOUT", "1m")
+
+	cfg := config.NewConfig(&flag.FlagSet{})
+	mdChannel := make(chan model.MarketData, 10)
+	c, err := NewCryptonatorClient(cfg, zap.NewNop(), server.Client(), mdChannel, crypto.NopReporter{}, crypto.NopDemand{})
+	assert.NoError(t, err)
+	client := c.(*cryptonatorClient)
+
+	// Los dos primeros intentos fallan y el tercero publica el precio
+	md, err := client.fetchMD(context.Background(), client.Pairs()[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "BTCUSD", md.Symbol)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// Con el proveedor caído no se publican precios vacíos y el circuito se abre
+	atomic.StoreInt32(&failing, 100)
+	client.poll(context.Background(), client.Pairs()[0])
+	assert.Len(t, mdChannel, 0)
+	assert.Equal(t, resilience.StateOpen, client.breaker.State())
+	assert.Equal(t, int32(6), atomic.LoadInt32(&requests))
+
+	// Con el circuito abierto no se consulta la API
+	_, err = client.fetchMD(context.Background(), client.Pairs()[0])
+	assert.ErrorIs(t, err, resilience.ErrCircuitOpen)
+	assert.Equal(t, int32(6), atomic.LoadInt32(&requests))
+}
+
+func TestRateLimitedBlocksScheduler(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.Header().Add("Retry-After", "120")
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_URL", server.URL)
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_PAIRS", "btc-usd;BTCUSD eth-usd;ETHUSD")
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_RETRY_ATTEMPTS", "3")
+
+	cfg := config.NewConfig(&flag.FlagSet{})
+	mdChannel := make(chan model.MarketData, 10)
+	c, err := NewCryptonatorClient(cfg, zap.NewNop(), server.Client(), mdChannel, crypto.NopReporter{}, crypto.NopDemand{})
+	assert.NoError(t, err)
+	client := c.(*cryptonatorClient)
+
+	pair, _, ok := client.scheduler.Next()
+	assert.True(t, ok)
+
+	// El 429 no se reintenta y suspende las consultas según el Retry-After
+	_, err = client.fetchMD(context.Background(), pair)
+	assert.ErrorIs(t, err, errRateLimited)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Len(t, mdChannel, 0)
+
+	_, wait, ok := client.scheduler.Next()
+	assert.False(t, ok)
+	assert.InDelta(t, (2 * time.Minute).Seconds(), wait.Seconds(), 5)
+}
+
+func TestStartStop(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write([]byte(`{"ticker":{"price":"1.5"},"timestamp":1628610304,"success":true,"error":""}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_URL", server.URL)
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_PAIRS", "btc-usd;BTCUSD")
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_POLL_INTERVAL", "1m")
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_WORKERS", "2")
+
+	cfg := config.NewConfig(&flag.FlagSet{})
+	mdChannel := make(chan model.MarketData)
+	client, err := NewCryptonatorClient(cfg, zap.NewNop(), server.Client(), mdChannel, crypto.NopReporter{}, crypto.NopDemand{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.Start(context.Background()))
+	assert.Equal(t, "BTCUSD", (<-mdChannel).Symbol)
+
+	// Stop no espera al próximo ciclo de consultas
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, client.Stop(ctx))
+}
+
+func TestStopCancelsInFlightRequests(t *testing.T) {
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		arrived <- struct{}{}
+		select {
+		case <-req.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_URL", server.URL)
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_PAIRS", "btc-usd;BTCUSD")
+	t.Setenv("MTZ_CRYPTO_API_CRYPTONATOR_POLL_INTERVAL", "1m")
+
+	cfg := config.NewConfig(&flag.FlagSet{})
+	client, err := NewCryptonatorClient(cfg, zap.NewNop(), server.Client(), make(model.MdChannel), crypto.NopReporter{}, crypto.NopDemand{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.Start(context.Background()))
+	<-arrived
+
+	// Stop cancela la consulta en curso en lugar de esperar la respuesta
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, client.Stop(ctx))
+}
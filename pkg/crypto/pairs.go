@@ -0,0 +1,176 @@
+package crypto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/shopspring/decimal"
+)
+
+// maxDecimals máxima cantidad de decimales aceptada por par
+const maxDecimals = 18
+
+// pairsFile estructura del archivo de pares (YAML o JSON). Los valores de
+// defaults se aplican a los pares que no los indican.
+type pairsFile struct {
+	Defaults pairEntry   `mapstructure:"defaults"`
+	Pairs    []pairEntry `mapstructure:"pairs"`
+}
+
+type pairEntry struct {
+	Symbol         string        `mapstructure:"symbol"`
+	ExternalSymbol string        `mapstructure:"externalSymbol"`
+	Provider       string        `mapstructure:"provider"`
+	Enabled        *bool         `mapstructure:"enabled"`
+	PollInterval   time.Duration `mapstructure:"pollInterval"`
+	Decimals       *int32        `mapstructure:"decimals"`
+	MaxStaleness   time.Duration `mapstructure:"maxStaleness"`
+}
+
+// LoadPairsFile lee y valida el archivo de pares. Devuelve sólo los pares habilitados.
+func LoadPairsFile(path string) ([]SymbolPair, error) {
+	var file pairsFile
+	if err := config.ReadFile(path, &file, config.ErrorUnused); err != nil {
+		return nil, fmt.Errorf("reading pairs file %s: %w", path, err)
+	}
+
+	pairs := []SymbolPair{}
+	errs := []string{}
+	seen := map[string]int{}
+
+	for i, entry := range file.Pairs {
+		entry = entry.withDefaults(file.Defaults)
+
+		pair := SymbolPair{
+			Symbol:         strings.TrimSpace(entry.Symbol),
+			ExternalSymbol: strings.TrimSpace(entry.ExternalSymbol),
+			Provider:       strings.TrimSpace(entry.Provider),
+			PollInterval:   entry.PollInterval,
+			Decimals:       entry.Decimals,
+			MaxStaleness:   entry.MaxStaleness,
+		}
+
+		if err := pair.validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("pairs[%d]: %s", i, err))
+			continue
+		}
+
+		key := pair.Provider + "/" + pair.Symbol
+		if first, found := seen[key]; found {
+			errs = append(errs, fmt.Sprintf("pairs[%d]: duplicated symbol %s for provider %s (see pairs[%d])",
+				i, pair.Symbol, pair.Provider, first))
+			continue
+		}
+		seen[key] = i
+
+		if entry.Enabled != nil && !*entry.Enabled {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid pairs file %s: %s", path, strings.Join(errs, "; "))
+	}
+
+	return pairs, nil
+}
+
+func (e pairEntry) withDefaults(defaults pairEntry) pairEntry {
+	if e.Provider == "" {
+		e.Provider = defaults.Provider
+	}
+	if e.Enabled == nil {
+		e.Enabled = defaults.Enabled
+	}
+	if e.PollInterval == 0 {
+		e.PollInterval = defaults.PollInterval
+	}
+	if e.Decimals == nil {
+		e.Decimals = defaults.Decimals
+	}
+	if e.MaxStaleness == 0 {
+		e.MaxStaleness = defaults.MaxStaleness
+	}
+
+	return e
+}
+
+func (p SymbolPair) validate() error {
+	switch {
+	case p.Symbol == "":
+		return fmt.Errorf("symbol is required")
+	case p.ExternalSymbol == "":
+		return fmt.Errorf("externalSymbol is required for %s", p.Symbol)
+	case p.Provider == "":
+		return fmt.Errorf("provider is required for %s", p.Symbol)
+	case p.PollInterval < 0:
+		return fmt.Errorf("pollInterval must not be negative for %s", p.Symbol)
+	case p.MaxStaleness < 0:
+		return fmt.Errorf("maxStaleness must not be negative for %s", p.Symbol)
+	case p.Decimals != nil && (*p.Decimals < 0 || *p.Decimals > maxDecimals):
+		return fmt.Errorf("decimals must be between 0 and %d for %s", maxDecimals, p.Symbol)
+	}
+
+	return nil
+}
+
+// ParsePairs interpreta pares con el formato 'simboloExterno;SIMBOLO' de los flags
+func ParsePairs(provider string, entries []string) ([]SymbolPair, error) {
+	pairs := []SymbolPair{}
+	errs := []string{}
+
+	for _, entry := range entries {
+		fields := strings.Split(entry, ";")
+		if len(fields) != 2 || strings.TrimSpace(fields[0]) == "" || strings.TrimSpace(fields[1]) == "" {
+			errs = append(errs, fmt.Sprintf("%q: expected 'externalSymbol;SYMBOL'", entry))
+			continue
+		}
+
+		pairs = append(pairs, SymbolPair{
+			Symbol:         strings.TrimSpace(fields[1]),
+			ExternalSymbol: strings.TrimSpace(fields[0]),
+			Provider:       provider,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid %s pairs: %s", provider, strings.Join(errs, "; "))
+	}
+
+	return pairs, nil
+}
+
+// ProviderPairs devuelve los pares habilitados de un proveedor: los del archivo
+// crypto.pairs.file si está configurado, o si no los del flag crypto.api.<proveedor>.pairs
+func ProviderPairs(cfg *config.Config, provider string) ([]SymbolPair, error) {
+	path := cfg.GetString("crypto.pairs.file")
+	if path == "" {
+		return ParsePairs(provider, cfg.GetStringSlice("crypto.api."+provider+".pairs"))
+	}
+
+	all, err := LoadPairsFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []SymbolPair{}
+	for _, pair := range all {
+		if pair.Provider == provider {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs, nil
+}
+
+// Round redondea el precio a los decimales configurados en el par, si los tiene
+func (p SymbolPair) Round(price decimal.Decimal) decimal.Decimal {
+	if p.Decimals == nil {
+		return price
+	}
+
+	return price.Round(*p.Decimals)
+}
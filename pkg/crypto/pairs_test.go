@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePairsFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadPairsFile(t *testing.T) {
+	pairs, err := LoadPairsFile("testdata/pairs.yaml")
+	assert.NoError(t, err)
+	assert.Len(t, pairs, 3)
+
+	btc := pairs[0]
+	assert.Equal(t, "BTCUSD", btc.Symbol)
+	assert.Equal(t, "btc-usd", btc.ExternalSymbol)
+	assert.Equal(t, "cryptonator", btc.Provider)
+	assert.Equal(t, 5*time.Second, btc.PollInterval)
+	assert.Equal(t, int32(0), *btc.Decimals)
+	assert.Equal(t, 2*time.Minute, btc.MaxStaleness)
+	assert.Equal(t, "45123", btc.Round(decimal.RequireFromString("45123.456")).String())
+
+	eth := pairs[1]
+	assert.Equal(t, time.Duration(0), eth.PollInterval)
+	assert.Equal(t, int32(2), *eth.Decimals)
+
+	coingecko := pairs[2]
+	assert.Equal(t, "coingecko", coingecko.Provider)
+	assert.Equal(t, 10*time.Minute, coingecko.MaxStaleness)
+}
+
+func TestLoadPairsFileJSON(t *testing.T) {
+	path := writePairsFile(t, "pairs.json", `{
+		"pairs": [{"symbol": "BTCUSD", "externalSymbol": "btcusdt", "provider": "binance", "pollInterval": "1s"}]
+	}`)
+
+	pairs, err := LoadPairsFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []SymbolPair{{
+		Symbol:         "BTCUSD",
+		ExternalSymbol: "btcusdt",
+		Provider:       "binance",
+		PollInterval:   time.Second,
+	}}, pairs)
+}
+
+func TestLoadPairsFileValidation(t *testing.T) {
+	path := writePairsFile(t, "pairs.yaml", `
+pairs:
+  - externalSymbol: btc-usd
+    provider: cryptonator
+  - symbol: ETHUSD
+    externalSymbol: eth-usd
+  - symbol: ADAUSD
+    externalSymbol: ada-usd
+    provider: cryptonator
+    decimals: 30
+  - symbol: DOTUSD
+    externalSymbol: dot-usd
+    provider: cryptonator
+  - symbol: DOTUSD
+    externalSymbol: dot-usd
+    provider: cryptonator
+`)
+
+	_, err := LoadPairsFile(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "pairs[0]: symbol is required")
+	assert.Contains(t, err.Error(), "pairs[1]: provider is required for ETHUSD")
+	assert.Contains(t, err.Error(), "pairs[2]: decimals must be between 0 and 18 for ADAUSD")
+	assert.Contains(t, err.Error(), "pairs[4]: duplicated symbol DOTUSD for provider cryptonator (see pairs[3])")
+
+	path = writePairsFile(t, "typo.yaml", `
+pairs:
+  - symbol: BTCUSD
+    externalSymbol: btc-usd
+    provider: cryptonator
+    pollIntervall: 5s
+`)
+
+	_, err = LoadPairsFile(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid keys: pollIntervall")
+
+	_, err = LoadPairsFile("testdata/missing.yaml")
+	assert.Error(t, err)
+}
+
+func TestParsePairs(t *testing.T) {
+	pairs, err := ParsePairs("cryptonator", []string{"btc-usd;BTCUSD", "eth-usd;ETHUSD"})
+	assert.NoError(t, err)
+	assert.Equal(t, []SymbolPair{
+		{Symbol: "BTCUSD", ExternalSymbol: "btc-usd", Provider: "cryptonator"},
+		{Symbol: "ETHUSD", ExternalSymbol: "eth-usd", Provider: "cryptonator"},
+	}, pairs)
+
+	_, err = ParsePairs("cryptonator", []string{"btc-usd", "eth-usd;", "ada-usd;ADAUSD"})
+	assert.EqualError(t, err,
+		`invalid cryptonator pairs: "btc-usd": expected 'externalSymbol;SYMBOL'; "eth-usd;": expected 'externalSymbol;SYMBOL'`)
+}
+
+func TestProviderPairs(t *testing.T) {
+	t.Setenv("MTZ_CRYPTO_PAIRS_FILE", "testdata/pairs.yaml")
+	cfg := config.NewConfig(&flag.FlagSet{})
+
+	pairs, err := ProviderPairs(cfg, "coingecko")
+	assert.NoError(t, err)
+	assert.Len(t, pairs, 1)
+	assert.Equal(t, "bitcoin/usd", pairs[0].ExternalSymbol)
+
+	t.Setenv("MTZ_CRYPTO_PAIRS_FILE", "")
+	t.Setenv("MTZ_CRYPTO_API_BINANCE_PAIRS", "btcusdt;BTCUSD")
+
+	pairs, err = ProviderPairs(cfg, "binance")
+	assert.NoError(t, err)
+	assert.Equal(t, []SymbolPair{{Symbol: "BTCUSD", ExternalSymbol: "btcusdt", Provider: "binance"}}, pairs)
+}
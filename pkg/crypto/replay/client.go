@@ -0,0 +1,285 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// ProviderName nombre del proveedor para configuración y agregación
+const ProviderName = "replay"
+
+// replayClient reproduce ticks guardados en archivos CSV (timestamp,symbol,price)
+// o JSONL ({"timestamp": ..., "symbol": ..., "price": ...})
+type replayClient struct {
+	logger    *zap.Logger
+	path      string
+	speed     float64
+	loop      bool
+	rebase    bool
+	mdChannel model.MdChannel
+	reporter  crypto.Reporter
+	routines  lifecycle.Group
+
+	symbolsOnce sync.Once
+	symbols     []string
+}
+
+type replayTick struct {
+	Timestamp json.RawMessage `json:"timestamp"`
+	Symbol    string          `json:"symbol"`
+	Price     decimal.Decimal `json:"price"`
+}
+
+func NewReplayClient(
+	config *config.Config,
+	logger *zap.Logger,
+	mdChannel model.MdChannel,
+	reporter crypto.Reporter,
+) crypto.Client {
+	return &replayClient{
+		logger:    logger,
+		path:      config.GetString("crypto.replay.file"),
+		speed:     config.GetFloat64("crypto.replay.speed"),
+		loop:      config.GetBool("crypto.replay.loop"),
+		rebase:    config.GetBool("crypto.replay.rebase"),
+		mdChannel: mdChannel,
+		reporter:  reporter,
+	}
+}
+
+// Symbols símbolos presentes en el archivo, ordenados. El archivo se lee una
+// sola vez; si no se puede leer no se informa ningún símbolo.
+func (c *replayClient) Symbols() []string {
+	c.symbolsOnce.Do(func() {
+		symbols, err := fileSymbols(c.path)
+		if err != nil {
+			c.logger.Error("error reading replay file symbols", zap.String("file", c.path), zap.Error(err))
+			return
+		}
+		c.symbols = symbols
+	})
+
+	return c.symbols
+}
+
+// fileSymbols lee los símbolos distintos de un archivo de ticks
+func fileSymbols(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	found := map[string]bool{}
+	err = readTicks(f, filepath.Ext(path), func(md model.MarketData) error {
+		found[md.Symbol] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(found))
+	for symbol := range found {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	return symbols, nil
+}
+
+func (c *replayClient) Start(ctx context.Context) error {
+	c.routines.Go(func(ctx context.Context) {
+		for {
+			if err := c.replayFile(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.logger.Error("error replaying market data file", zap.String("file", c.path), zap.Error(err))
+				c.reporter.Failure("", err)
+				return
+			}
+
+			if !c.loop {
+				c.logger.Info("market data replay finished", zap.String("file", c.path))
+				return
+			}
+		}
+	})
+
+	c.logger.Info("replayClient started",
+		zap.String("file", c.path),
+		zap.Float64("speed", c.speed),
+		zap.Bool("loop", c.loop))
+
+	return nil
+}
+
+func (c *replayClient) Stop(ctx context.Context) error {
+	return c.routines.Stop(ctx)
+}
+
+// errNoTicks el archivo no tiene ticks; en loop la reproducción no terminaría
+// nunca de esperar ni de publicar
+var errNoTicks = errors.New("replay file has no ticks")
+
+// replayFile publica todos los ticks del archivo respetando, según la velocidad
+// configurada, el tiempo transcurrido entre ellos
+func (c *replayClient) replayFile(ctx context.Context) error {
+	f, err := os.Open(c.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var first time.Time
+	start := time.Now()
+
+	err = readTicks(f, filepath.Ext(c.path), func(md model.MarketData) error {
+		if first.IsZero() {
+			first = md.LastPriceDateTime
+		}
+
+		offset := md.LastPriceDateTime.Sub(first)
+		if c.speed > 0 {
+			offset = time.Duration(float64(offset) / c.speed)
+			if wait := time.Until(start.Add(offset)); wait > 0 && !lifecycle.Sleep(ctx, wait) {
+				return ctx.Err()
+			}
+		}
+
+		if c.rebase {
+			md.LastPriceDateTime = start.Add(offset)
+		}
+
+		c.reporter.Success(md.Symbol)
+
+		select {
+		case c.mdChannel <- md:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	if err == nil && first.IsZero() {
+		return errNoTicks
+	}
+
+	return err
+}
+
+// readTicks interpreta el contenido según la extensión del archivo (.csv o .jsonl)
+func readTicks(r io.Reader, ext string, fn func(md model.MarketData) error) error {
+	switch strings.ToLower(ext) {
+	case ".csv":
+		return readCSV(r, fn)
+	case ".jsonl", ".ndjson":
+		return readJSONL(r, fn)
+	default:
+		return fmt.Errorf("unsupported replay file format: %q", ext)
+	}
+}
+
+func readCSV(r io.Reader, fn func(md model.MarketData) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		timestamp, err := parseTimestamp(record[0])
+		if err != nil {
+			// La primera línea puede ser el encabezado
+			if line == 1 {
+				continue
+			}
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		price, err := decimal.NewFromString(record[2])
+		if err != nil {
+			return fmt.Errorf("line %d: invalid price: %w", line, err)
+		}
+
+		if err := fn(model.MarketData{
+			Symbol:            record[1],
+			LastPrice:         price,
+			LastPriceDateTime: timestamp,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func readJSONL(r io.Reader, fn func(md model.MarketData) error) error {
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var tick replayTick
+		if err := json.Unmarshal(scanner.Bytes(), &tick); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		timestamp, err := parseTimestamp(strings.Trim(string(tick.Timestamp), `"`))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if err := fn(model.MarketData{
+			Symbol:            tick.Symbol,
+			LastPrice:         tick.Price,
+			LastPriceDateTime: timestamp,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseTimestamp acepta RFC3339 o epoch en segundos o milisegundos
+func parseTimestamp(value string) (time.Time, error) {
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if epoch > 1e12 {
+			return time.UnixMilli(epoch), nil
+		}
+		return time.Unix(epoch, 0), nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return timestamp, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	return timestamp, nil
+}
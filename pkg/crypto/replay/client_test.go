@@ -0,0 +1,121 @@
+package replay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newTestClient(t *testing.T, name, content string, loop bool) (*replayClient, model.MdChannel) {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	mdChannel := make(model.MdChannel)
+	client := &replayClient{
+		logger:    zap.NewNop(),
+		path:      path,
+		loop:      loop,
+		mdChannel: mdChannel,
+		reporter:  crypto.NopReporter{},
+	}
+
+	return client, mdChannel
+}
+
+func TestReplayCSV(t *testing.T) {
+	client, mdChannel := newTestClient(t, "ticks.csv", `timestamp,symbol,price
+2021-08-10T15:45:04Z,BTCUSD,45123.45
+1628610305,ETHUSD,3012.5
+`, false)
+	assert.NoError(t, client.Start(context.Background()))
+
+	md := <-mdChannel
+	assert.Equal(t, "BTCUSD", md.Symbol)
+	assert.Equal(t, decimal.RequireFromString("45123.45"), md.LastPrice)
+	assert.Equal(t, int64(1_628_610_304), md.LastPriceDateTime.Unix())
+
+	md = <-mdChannel
+	assert.Equal(t, "ETHUSD", md.Symbol)
+	assert.Equal(t, int64(1_628_610_305), md.LastPriceDateTime.Unix())
+
+	assert.Equal(t, []string{"BTCUSD", "ETHUSD"}, client.Symbols())
+}
+
+func TestReplayJSONLLoop(t *testing.T) {
+	client, mdChannel := newTestClient(t, "ticks.jsonl", `{"timestamp":"2021-08-10T15:45:04Z","symbol":"BTCUSD","price":"45123.45"}
+{"timestamp":1628610305000,"symbol":"BTCUSD","price":45200}
+`, true)
+	assert.NoError(t, client.Start(context.Background()))
+
+	prices := []string{}
+	for i := 0; i < 4; i++ {
+		md := <-mdChannel
+		prices = append(prices, md.LastPrice.String())
+	}
+
+	assert.Equal(t, []string{"45123.45", "45200", "45123.45", "45200"}, prices)
+
+	// Stop corta la reproducción aunque nadie consuma el channel
+	assert.NoError(t, client.Stop(context.Background()))
+}
+
+func TestReplayAccelerated(t *testing.T) {
+	client, mdChannel := newTestClient(t, "ticks.csv", `1628610300,BTCUSD,1
+1628610301,BTCUSD,2
+`, false)
+	client.speed = 10
+	client.rebase = true
+	assert.NoError(t, client.Start(context.Background()))
+
+	first := <-mdChannel
+	second := <-mdChannel
+
+	gap := second.LastPriceDateTime.Sub(first.LastPriceDateTime)
+	assert.Equal(t, 100*time.Millisecond, gap)
+	assert.WithinDuration(t, time.Now(), second.LastPriceDateTime, time.Second)
+}
+
+func TestReadTicksInvalidLine(t *testing.T) {
+	client, _ := newTestClient(t, "ticks.csv", `1628610300,BTCUSD,1
+not-a-date,BTCUSD,2
+`, false)
+	client.mdChannel = make(model.MdChannel, 10)
+
+	err := client.replayFile(context.Background())
+	assert.EqualError(t, err, `line 2: invalid timestamp "not-a-date"`)
+}
+
+// failureReporter informa los errores recibidos
+type failureReporter struct {
+	crypto.NopReporter
+	failures chan error
+}
+
+func (r failureReporter) Failure(symbol string, err error) {
+	r.failures <- err
+}
+
+func TestReplayEmptyFileLoop(t *testing.T) {
+	client, _ := newTestClient(t, "ticks.csv", "timestamp,symbol,price\n", true)
+	reporter := failureReporter{failures: make(chan error, 1)}
+	client.reporter = reporter
+	assert.NoError(t, client.Start(context.Background()))
+
+	// Sin ticks la reproducción termina con error en lugar de repetirse sin pausa
+	select {
+	case err := <-reporter.failures:
+		assert.ErrorIs(t, err, errNoTicks)
+	case <-time.After(time.Second):
+		t.Fatal("replay did not fail")
+	}
+	assert.NoError(t, client.Stop(context.Background()))
+	assert.Empty(t, client.Symbols())
+}
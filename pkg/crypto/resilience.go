@@ -0,0 +1,25 @@
+package crypto
+
+import (
+	"fmt"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/resilience"
+)
+
+// ProviderRetryPolicy política de reintentos configurada para un proveedor
+func ProviderRetryPolicy(cfg *config.Config, provider string) resilience.RetryPolicy {
+	return resilience.RetryPolicy{
+		MaxAttempts: cfg.GetInt(fmt.Sprintf("crypto.api.%s.retry.attempts", provider)),
+		MinDelay:    cfg.GetDuration(fmt.Sprintf("crypto.api.%s.retry.min", provider)),
+		MaxDelay:    cfg.GetDuration(fmt.Sprintf("crypto.api.%s.retry.max", provider)),
+	}
+}
+
+// ProviderCircuitBreaker circuit breaker configurado para un proveedor
+func ProviderCircuitBreaker(cfg *config.Config, provider string) *resilience.CircuitBreaker {
+	return resilience.NewCircuitBreaker(
+		cfg.GetInt(fmt.Sprintf("crypto.api.%s.breaker.failures", provider)),
+		cfg.GetDuration(fmt.Sprintf("crypto.api.%s.breaker.open.timeout", provider)),
+	)
+}
@@ -0,0 +1,182 @@
+package crypto
+
+import (
+	"sync"
+	"time"
+)
+
+// Demand indica qué símbolos son referenciados con frecuencia por las wallets
+type Demand interface {
+	Hot(symbol string) bool
+}
+
+// NopDemand considera a todos los símbolos fríos
+type NopDemand struct{}
+
+func (NopDemand) Hot(symbol string) bool { return false }
+
+// Scheduler decide qué par consultar y cuándo, respetando un presupuesto de
+// requests por ventana de tiempo y los bloqueos por Retry-After. Las consultas
+// se distribuyen de forma pareja en el intervalo, y los símbolos calientes se
+// consultan con hotInterval en lugar del intervalo del par.
+type Scheduler struct {
+	interval    time.Duration
+	hotInterval time.Duration
+	// budgetGap separación mínima entre requests según el presupuesto (0: sin límite)
+	budgetGap time.Duration
+	demand    Demand
+	now       func() time.Time
+
+	mu           sync.Mutex
+	pairs        []SymbolPair
+	nextPoll     map[string]time.Time
+	lastPoll     time.Time
+	lastRequest  time.Time
+	blockedUntil time.Time
+}
+
+// NewScheduler crea un scheduler. budget es la cantidad de requests permitidos
+// por window; con budget <= 0 no hay límite.
+func NewScheduler(
+	interval time.Duration,
+	hotInterval time.Duration,
+	budget int,
+	window time.Duration,
+	demand Demand,
+) *Scheduler {
+	var budgetGap time.Duration
+	if budget > 0 {
+		budgetGap = window / time.Duration(budget)
+	}
+
+	return &Scheduler{
+		interval:    interval,
+		hotInterval: hotInterval,
+		budgetGap:   budgetGap,
+		demand:      demand,
+		now:         time.Now,
+		nextPoll:    make(map[string]time.Time),
+	}
+}
+
+func (s *Scheduler) Pairs() []SymbolPair {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SymbolPair{}, s.pairs...)
+}
+
+// SetPairs reemplaza los pares. Los pares nuevos se consultan lo antes posible.
+func (s *Scheduler) SetPairs(pairs []SymbolPair) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pairs = append([]SymbolPair{}, pairs...)
+
+	current := make(map[string]time.Time, len(pairs))
+	for _, pair := range pairs {
+		if next, found := s.nextPoll[pair.Symbol]; found {
+			current[pair.Symbol] = next
+		}
+	}
+	s.nextPoll = current
+}
+
+// Block suspende las consultas hasta until (ej. por un header Retry-After)
+func (s *Scheduler) Block(until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if until.After(s.blockedUntil) {
+		s.blockedUntil = until
+	}
+}
+
+// Next devuelve el próximo par a consultar y lo agenda. Si todavía no hay uno
+// disponible devuelve ok=false y el tiempo a esperar antes de volver a llamar.
+func (s *Scheduler) Next() (pair SymbolPair, wait time.Duration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if len(s.pairs) == 0 {
+		return pair, s.interval, false
+	}
+
+	// Par más atrasado; los que nunca se consultaron van primero
+	pair = s.pairs[0]
+	due := s.nextPoll[pair.Symbol]
+	for _, p := range s.pairs[1:] {
+		if next := s.nextPoll[p.Symbol]; next.Before(due) {
+			pair, due = p, next
+		}
+	}
+
+	ready := latest(due, s.lastPoll.Add(s.evenGap()), s.lastRequest.Add(s.budgetGap), s.blockedUntil)
+	if ready.After(now) {
+		return SymbolPair{}, ready.Sub(now), false
+	}
+
+	// La siguiente consulta se agenda desde la prevista, para que las demoras
+	// por la distribución o el presupuesto no se acumulen
+	interval := s.pairInterval(pair)
+	if due.IsZero() || now.Sub(due) > interval {
+		due = now
+	}
+
+	s.lastPoll = now
+	s.lastRequest = now
+	s.nextPoll[pair.Symbol] = due.Add(interval)
+
+	return pair, 0, true
+}
+
+// Wait reserva un request fuera del ciclo de consultas (ej. un reintento) y
+// devuelve el tiempo a esperar para respetar el presupuesto
+func (s *Scheduler) Wait() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	slot := latest(now, s.lastRequest.Add(s.budgetGap), s.blockedUntil)
+	s.lastRequest = slot
+
+	return slot.Sub(now)
+}
+
+// pairInterval intervalo de consulta del par según su demanda. Requiere s.mu.
+func (s *Scheduler) pairInterval(pair SymbolPair) time.Duration {
+	interval := pair.PollInterval
+	if interval <= 0 {
+		interval = s.interval
+	}
+
+	if s.hotInterval > 0 && s.hotInterval < interval && s.demand.Hot(pair.Symbol) {
+		interval = s.hotInterval
+	}
+
+	return interval
+}
+
+// evenGap separación entre consultas para distribuirlas de forma pareja: la
+// inversa de la cantidad total de consultas por segundo de todos los pares.
+// Requiere s.mu.
+func (s *Scheduler) evenGap() time.Duration {
+	rate := 0.0
+	for _, pair := range s.pairs {
+		rate += 1 / s.pairInterval(pair).Seconds()
+	}
+
+	return time.Duration(float64(time.Second) / rate)
+}
+
+func latest(times ...time.Time) time.Time {
+	rs := times[0]
+	for _, t := range times[1:] {
+		if t.After(rs) {
+			rs = t
+		}
+	}
+
+	return rs
+}
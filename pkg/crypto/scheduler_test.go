@@ -0,0 +1,123 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hotSymbols map[string]bool
+
+func (h hotSymbols) Hot(symbol string) bool { return h[symbol] }
+
+func newTestScheduler(now *time.Time, budget int, demand Demand) *Scheduler {
+	s := NewScheduler(12*time.Second, 4*time.Second, budget, time.Minute, demand)
+	s.now = func() time.Time { return *now }
+	s.SetPairs([]SymbolPair{
+		{Symbol: "BTCUSD", ExternalSymbol: "btc-usd"},
+		{Symbol: "ETHUSD", ExternalSymbol: "eth-usd"},
+		{Symbol: "ADAUSD", ExternalSymbol: "ada-usd"},
+	})
+
+	return s
+}
+
+// drain avanza el reloj consultando los pares disponibles hasta until
+func drain(s *Scheduler, now *time.Time, until time.Time) (polls []string) {
+	for now.Before(until) {
+		pair, wait, ok := s.Next()
+		if !ok {
+			*now = now.Add(wait)
+			continue
+		}
+		polls = append(polls, pair.Symbol)
+	}
+
+	return polls
+}
+
+func TestSchedulerSpreadsRequestsEvenly(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	s := newTestScheduler(&now, 0, NopDemand{})
+
+	pair, _, ok := s.Next()
+	assert.True(t, ok)
+	assert.Equal(t, "BTCUSD", pair.Symbol)
+
+	// 3 pares cada 12s: una consulta cada 4s
+	_, wait, ok := s.Next()
+	assert.False(t, ok)
+	assert.Equal(t, 4*time.Second, wait)
+
+	start := now
+	polls := drain(s, &now, start.Add(time.Minute))
+	assert.Len(t, polls, 14)
+	assert.Equal(t, []string{"ETHUSD", "ADAUSD", "BTCUSD", "ETHUSD"}, polls[:4])
+}
+
+func TestSchedulerPollsHotSymbolsMoreOften(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	s := newTestScheduler(&now, 0, hotSymbols{"BTCUSD": true})
+
+	counts := map[string]int{}
+	for _, symbol := range drain(s, &now, now.Add(2*time.Minute)) {
+		counts[symbol]++
+	}
+
+	assert.Greater(t, counts["BTCUSD"], 2*counts["ETHUSD"])
+	assert.InDelta(t, counts["ETHUSD"], counts["ADAUSD"], 1)
+}
+
+func TestSchedulerRespectsBudgetAndRetryAfter(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	s := newTestScheduler(&now, 6, NopDemand{})
+
+	// 6 requests por minuto: uno cada 10s aunque los pares pidan uno cada 4s
+	start := now
+	assert.Len(t, drain(s, &now, start.Add(time.Minute)), 6)
+
+	// Los reintentos también consumen presupuesto
+	assert.Equal(t, time.Duration(0), s.Wait())
+	assert.Equal(t, 10*time.Second, s.Wait())
+	_, wait, _ := s.Next()
+	assert.Equal(t, 20*time.Second, wait)
+
+	// Retry-After bloquea las consultas
+	s.Block(now.Add(5 * time.Minute))
+	_, wait, ok := s.Next()
+	assert.False(t, ok)
+	assert.Equal(t, 5*time.Minute, wait)
+}
+
+func TestSchedulerSetPairs(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	s := newTestScheduler(&now, 0, NopDemand{})
+	drain(s, &now, now.Add(12*time.Second))
+
+	s.SetPairs([]SymbolPair{{Symbol: "BTCUSD"}, {Symbol: "DOTUSD"}})
+	now = now.Add(time.Minute)
+
+	pair, _, ok := s.Next()
+	assert.True(t, ok)
+	assert.Equal(t, "DOTUSD", pair.Symbol)
+	assert.Len(t, s.Pairs(), 2)
+}
+
+func TestSchedulerPerPairPollInterval(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	s := NewScheduler(15*time.Second, 0, 0, time.Minute, NopDemand{})
+	s.now = func() time.Time { return now }
+	s.SetPairs([]SymbolPair{
+		{Symbol: "BTCUSD", ExternalSymbol: "btc-usd", PollInterval: 5 * time.Second},
+		{Symbol: "ETHUSD", ExternalSymbol: "eth-usd"},
+	})
+
+	counts := map[string]int{}
+	for _, symbol := range drain(s, &now, now.Add(time.Minute)) {
+		counts[symbol]++
+	}
+
+	assert.Equal(t, 12, counts["BTCUSD"])
+	assert.Equal(t, 4, counts["ETHUSD"])
+}
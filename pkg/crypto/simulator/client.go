@@ -0,0 +1,207 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// ProviderName nombre del proveedor para configuración y agregación
+const ProviderName = "simulator"
+
+// year duración usada para anualizar drift y volatilidad
+const year = 365 * 24 * time.Hour
+
+// minTickPeriod resolución mínima del timer; a tasas mayores se emiten varios ticks por período
+const minTickPeriod = time.Millisecond
+
+// simulatorClient genera precios sintéticos con movimiento browniano geométrico y saltos
+type simulatorClient struct {
+	logger    *zap.Logger
+	series    []*series
+	mdChannel model.MdChannel
+	reporter  crypto.Reporter
+	routines  lifecycle.Group
+}
+
+// seriesSpec parámetros de simulación de un símbolo
+type seriesSpec struct {
+	Symbol     string
+	Price      float64
+	Drift      float64
+	Volatility float64
+	JumpProb   float64
+	TickRate   float64
+}
+
+// series estado de la simulación de un símbolo
+type series struct {
+	spec     seriesSpec
+	price    float64
+	jumpSize float64
+	decimals int32
+	rnd      *rand.Rand
+}
+
+func NewSimulatorClient(
+	config *config.Config,
+	logger *zap.Logger,
+	mdChannel model.MdChannel,
+	reporter crypto.Reporter,
+) crypto.Client {
+	seed := config.GetInt64("crypto.simulator.seed")
+	jumpSize := config.GetFloat64("crypto.simulator.jump.size")
+	decimals := int32(config.GetInt("crypto.simulator.decimals"))
+
+	allSeries := []*series{}
+	for i, specStr := range config.GetStringSlice("crypto.simulator.symbols") {
+		spec, err := parseSeriesSpec(specStr)
+		if err != nil {
+			logger.Error("invalid simulator symbol", zap.String("symbol", specStr), zap.Error(err))
+			continue
+		}
+
+		allSeries = append(allSeries, newSeries(spec, seed+int64(i), jumpSize, decimals))
+	}
+
+	return &simulatorClient{
+		logger:    logger,
+		series:    allSeries,
+		mdChannel: mdChannel,
+		reporter:  reporter,
+	}
+}
+
+func newSeries(spec seriesSpec, seed int64, jumpSize float64, decimals int32) *series {
+	return &series{
+		spec:     spec,
+		price:    spec.Price,
+		jumpSize: jumpSize,
+		decimals: decimals,
+		rnd:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// parseSeriesSpec interpreta 'simbolo;precio;drift;volatilidad;probabilidad de salto;ticks por segundo'
+func parseSeriesSpec(specStr string) (spec seriesSpec, err error) {
+	fields := strings.Split(specStr, ";")
+	if len(fields) != 6 || fields[0] == "" {
+		return spec, fmt.Errorf("expected 'symbol;price;drift;volatility;jumpProb;tickRate'")
+	}
+
+	values := make([]float64, 5)
+	for i, field := range fields[1:] {
+		values[i], err = strconv.ParseFloat(field, 64)
+		if err != nil {
+			return spec, fmt.Errorf("invalid value %q: %w", field, err)
+		}
+	}
+
+	spec = seriesSpec{
+		Symbol:     fields[0],
+		Price:      values[0],
+		Drift:      values[1],
+		Volatility: values[2],
+		JumpProb:   values[3],
+		TickRate:   values[4],
+	}
+
+	if spec.Price <= 0 || spec.TickRate <= 0 {
+		return spec, fmt.Errorf("price and tick rate must be positive")
+	}
+
+	return spec, nil
+}
+
+func (c *simulatorClient) Symbols() []string {
+	symbols := make([]string, 0, len(c.series))
+	for _, s := range c.series {
+		symbols = append(symbols, s.spec.Symbol)
+	}
+
+	return symbols
+}
+
+func (c *simulatorClient) Start(ctx context.Context) error {
+	for _, s := range c.series {
+		s := s
+		c.routines.Go(func(ctx context.Context) { c.run(ctx, s) })
+	}
+
+	c.logger.Info("simulatorClient started", zap.Int("symbols", len(c.series)))
+
+	return nil
+}
+
+func (c *simulatorClient) Stop(ctx context.Context) error {
+	return c.routines.Stop(ctx)
+}
+
+// run publica los ticks de una serie a la tasa configurada. Si la tasa supera
+// la resolución del timer se emiten en ráfagas los ticks adeudados.
+func (c *simulatorClient) run(ctx context.Context, s *series) {
+	interval := time.Duration(float64(time.Second) / s.spec.TickRate)
+	period := interval
+	if period < minTickPeriod {
+		period = minTickPeriod
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	start := time.Now()
+	emitted := int64(0)
+
+	for {
+		var now time.Time
+		select {
+		case now = <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		due := int64(now.Sub(start).Seconds() * s.spec.TickRate)
+		for ; emitted < due; emitted++ {
+			md := model.MarketData{
+				Symbol:            s.spec.Symbol,
+				LastPrice:         s.next(interval),
+				LastPriceDateTime: now,
+			}
+
+			select {
+			case c.mdChannel <- md:
+			case <-ctx.Done():
+				return
+			}
+		}
+		c.reporter.Success(s.spec.Symbol)
+	}
+}
+
+// next avanza la serie un paso dt y devuelve el nuevo precio:
+// S(t+dt) = S(t) * exp((mu - sigma^2/2) dt + sigma sqrt(dt) Z) * J
+func (s *series) next(dt time.Duration) decimal.Decimal {
+	t := dt.Seconds() / year.Seconds()
+	mu := s.spec.Drift
+	sigma := s.spec.Volatility
+
+	logReturn := (mu-sigma*sigma/2)*t + sigma*math.Sqrt(t)*s.rnd.NormFloat64()
+	if s.spec.JumpProb > 0 && s.rnd.Float64() < s.spec.JumpProb {
+		logReturn += s.jumpSize * s.rnd.NormFloat64()
+	}
+
+	s.price *= math.Exp(logReturn)
+
+	return decimal.NewFromFloat(s.price).Round(s.decimals)
+}
@@ -0,0 +1,64 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSeriesIsReproducible(t *testing.T) {
+	spec := seriesSpec{Symbol: "BTCUSD", Price: 45000, Drift: 0.05, Volatility: 0.8, JumpProb: 0.1, TickRate: 10}
+
+	s1 := newSeries(spec, 42, 0.05, 8)
+	s2 := newSeries(spec, 42, 0.05, 8)
+	s3 := newSeries(spec, 43, 0.05, 8)
+
+	for i := 0; i < 100; i++ {
+		p1 := s1.next(100 * time.Millisecond)
+		p2 := s2.next(100 * time.Millisecond)
+		p3 := s3.next(100 * time.Millisecond)
+
+		assert.True(t, p1.Equal(p2))
+		assert.True(t, p1.IsPositive())
+		if i == 99 {
+			assert.False(t, p1.Equal(p3))
+		}
+	}
+}
+
+func TestParseSeriesSpec(t *testing.T) {
+	spec, err := parseSeriesSpec("ETHUSD;3000;0.1;0.9;0.01;1000")
+	assert.NoError(t, err)
+	assert.Equal(t, seriesSpec{Symbol: "ETHUSD", Price: 3000, Drift: 0.1, Volatility: 0.9, JumpProb: 0.01, TickRate: 1000}, spec)
+
+	_, err = parseSeriesSpec("ETHUSD;3000;0.1")
+	assert.Error(t, err)
+
+	_, err = parseSeriesSpec("ETHUSD;3000;0.1;0.9;0.01;0")
+	assert.Error(t, err)
+}
+
+func TestHighTickRate(t *testing.T) {
+	spec := seriesSpec{Symbol: "BTCUSD", Price: 45000, Volatility: 0.8, TickRate: 5000}
+	mdChannel := make(model.MdChannel, 100)
+	client := &simulatorClient{
+		logger:    zap.NewNop(),
+		series:    []*series{newSeries(spec, 1, 0, 8)},
+		mdChannel: mdChannel,
+		reporter:  crypto.NopReporter{},
+	}
+	assert.NoError(t, client.Start(context.Background()))
+
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		md := <-mdChannel
+		assert.Equal(t, "BTCUSD", md.Symbol)
+	}
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.NoError(t, client.Stop(context.Background()))
+}
@@ -0,0 +1,159 @@
+package election
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"go.uber.org/zap"
+)
+
+// ErrLockLost el lock dejó de pertenecer a esta réplica
+var ErrLockLost = errors.New("leader lock lost")
+
+// Lock lock distribuido que identifica al líder entre las réplicas
+type Lock interface {
+	// TryAcquire intenta obtener el lock sin bloquear
+	TryAcquire(ctx context.Context) (acquired bool, err error)
+	// Check verifica que el lock se conserve
+	Check(ctx context.Context) error
+	// Release libera el lock, si se tiene
+	Release(ctx context.Context) error
+}
+
+// Elector compite periódicamente por el liderazgo. Mientras lo tiene mantiene
+// iniciado el componente del líder; si lo pierde, lo detiene.
+type Elector interface {
+	lifecycle.Component
+	IsLeader() bool
+}
+
+type elector struct {
+	logger      *zap.Logger
+	lock        Lock
+	leader      lifecycle.Component
+	interval    time.Duration
+	stopTimeout time.Duration
+	routines    lifecycle.Group
+
+	mu      sync.Mutex
+	leading bool
+}
+
+// NewElector crea el elector. interval es la frecuencia con la que se intenta
+// obtener o se verifica el lock, y stopTimeout el tiempo máximo para detener el
+// componente del líder al perder el liderazgo.
+func NewElector(
+	logger *zap.Logger,
+	lock Lock,
+	leader lifecycle.Component,
+	interval time.Duration,
+	stopTimeout time.Duration,
+) Elector {
+	return &elector{
+		logger:      logger,
+		lock:        lock,
+		leader:      leader,
+		interval:    interval,
+		stopTimeout: stopTimeout,
+	}
+}
+
+func (e *elector) Start(ctx context.Context) error {
+	e.routines.Go(func(ctx context.Context) {
+		for {
+			e.campaign(ctx)
+
+			if !lifecycle.Sleep(ctx, e.interval) {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (e *elector) Stop(ctx context.Context) error {
+	if err := e.routines.Stop(ctx); err != nil {
+		return err
+	}
+
+	if !e.IsLeader() {
+		return nil
+	}
+
+	return e.resign(ctx)
+}
+
+func (e *elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.leading
+}
+
+// campaign intenta obtener el liderazgo o, si ya se tiene, verifica que se conserve
+func (e *elector) campaign(ctx context.Context) {
+	if e.IsLeader() {
+		if err := e.lock.Check(ctx); err != nil {
+			e.logger.Warn("leadership lost", zap.Error(err))
+
+			stopCtx, cancel := context.WithTimeout(context.Background(), e.stopTimeout)
+			defer cancel()
+			if err := e.resign(stopCtx); err != nil {
+				e.logger.Error("error resigning leadership", zap.Error(err))
+			}
+		}
+		return
+	}
+
+	acquired, err := e.lock.TryAcquire(ctx)
+	if err != nil {
+		e.logger.Warn("error acquiring leader lock", zap.Error(err))
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	if err := e.leader.Start(ctx); err != nil {
+		e.logger.Error("error starting leader components", zap.Error(err))
+		if err := e.lock.Release(ctx); err != nil {
+			e.logger.Warn("error releasing leader lock", zap.Error(err))
+		}
+		return
+	}
+
+	e.mu.Lock()
+	e.leading = true
+	e.mu.Unlock()
+
+	e.logger.Info("elected leader")
+}
+
+// resign detiene el componente del líder y libera el lock
+func (e *elector) resign(ctx context.Context) error {
+	e.mu.Lock()
+	e.leading = false
+	e.mu.Unlock()
+
+	errs := []string{}
+	if err := e.leader.Stop(ctx); err != nil {
+		errs = append(errs, fmt.Sprintf("stopping leader components: %s", err))
+	}
+	if err := e.lock.Release(ctx); err != nil {
+		errs = append(errs, fmt.Sprintf("releasing leader lock: %s", err))
+	}
+
+	e.logger.Info("resigned leadership")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package election
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// replica elector con un componente de líder que registra si está iniciado
+type replica struct {
+	elector Elector
+	running int32
+}
+
+func newReplica(mutex *MemoryMutex) *replica {
+	r := &replica{}
+	r.elector = NewElector(zap.NewNop(), NewMemoryLock(mutex), lifecycle.Hook{
+		OnStart: func(ctx context.Context) error {
+			atomic.StoreInt32(&r.running, 1)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			atomic.StoreInt32(&r.running, 0)
+			return nil
+		},
+	}, time.Millisecond, time.Second)
+	return r
+}
+
+func (r *replica) leading() bool {
+	return r.elector.IsLeader() && atomic.LoadInt32(&r.running) == 1
+}
+
+func (r *replica) idle() bool {
+	return !r.elector.IsLeader() && atomic.LoadInt32(&r.running) == 0
+}
+
+func TestElectorSingleLeaderAndFailover(t *testing.T) {
+	mutex := &MemoryMutex{}
+	first, second := newReplica(mutex), newReplica(mutex)
+
+	require.NoError(t, first.elector.Start(context.Background()))
+	assert.Eventually(t, first.leading, time.Second, time.Millisecond)
+
+	require.NoError(t, second.elector.Start(context.Background()))
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, second.idle())
+
+	// Al detenerse el líder, la otra réplica toma el liderazgo
+	require.NoError(t, first.elector.Stop(context.Background()))
+	assert.True(t, first.idle())
+	assert.Eventually(t, second.leading, time.Second, time.Millisecond)
+
+	require.NoError(t, second.elector.Stop(context.Background()))
+	assert.True(t, second.idle())
+}
+
+func TestElectorResignsWhenLockIsLost(t *testing.T) {
+	mutex := &MemoryMutex{}
+	first := newReplica(mutex)
+
+	require.NoError(t, first.elector.Start(context.Background()))
+	assert.Eventually(t, first.leading, time.Second, time.Millisecond)
+
+	// El lock vence y lo obtiene otra réplica antes de que el líder lo verifique
+	other := NewMemoryLock(mutex)
+	mutex.mu.Lock()
+	mutex.holder = other.(*memoryLock)
+	mutex.mu.Unlock()
+
+	assert.Eventually(t, first.idle, time.Second, time.Millisecond)
+
+	// Liberado el lock, el elector lo vuelve a obtener
+	require.NoError(t, other.Release(context.Background()))
+	assert.Eventually(t, first.leading, time.Second, time.Millisecond)
+
+	require.NoError(t, first.elector.Stop(context.Background()))
+}
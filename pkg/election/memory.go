@@ -0,0 +1,64 @@
+package election
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryMutex lock en memoria compartido por los MemoryLock de varias réplicas
+// de un mismo proceso, para tests. El valor cero está listo para usar.
+type MemoryMutex struct {
+	mu     sync.Mutex
+	holder *memoryLock
+}
+
+// Expire libera el lock como si el líder hubiera muerto
+func (m *MemoryMutex) Expire() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.holder = nil
+}
+
+type memoryLock struct {
+	mutex *MemoryMutex
+}
+
+// NewMemoryLock crea el lock de una réplica sobre el mutex compartido
+func NewMemoryLock(mutex *MemoryMutex) Lock {
+	return &memoryLock{mutex: mutex}
+}
+
+func (l *memoryLock) TryAcquire(ctx context.Context) (acquired bool, err error) {
+	l.mutex.mu.Lock()
+	defer l.mutex.mu.Unlock()
+
+	if l.mutex.holder != nil && l.mutex.holder != l {
+		return false, nil
+	}
+	l.mutex.holder = l
+
+	return true, nil
+}
+
+func (l *memoryLock) Check(ctx context.Context) error {
+	l.mutex.mu.Lock()
+	defer l.mutex.mu.Unlock()
+
+	if l.mutex.holder != l {
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+func (l *memoryLock) Release(ctx context.Context) error {
+	l.mutex.mu.Lock()
+	defer l.mutex.mu.Unlock()
+
+	if l.mutex.holder == l {
+		l.mutex.holder = nil
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package election
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+)
+
+// postgresLock lock basado en un advisory lock de sesión de PostgreSQL. El lock
+// pertenece a una conexión dedicada: si la réplica muere, la conexión se cierra
+// y PostgreSQL libera el lock.
+type postgresLock struct {
+	db  *sql.DB
+	key int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewPostgresLock crea el lock identificado por key. Todas las réplicas deben
+// usar la misma key.
+func NewPostgresLock(db *sql.DB, key int64) Lock {
+	return &postgresLock{db: db, key: key}
+}
+
+func (l *postgresLock) TryAcquire(ctx context.Context) (acquired bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		l.conn, err = l.db.Conn(ctx)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = l.conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired)
+	if err != nil || !acquired {
+		l.closeConn()
+	}
+
+	return acquired, err
+}
+
+func (l *postgresLock) Check(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return ErrLockLost
+	}
+
+	// Si la conexión se cortó, PostgreSQL ya liberó el lock
+	if err := l.conn.PingContext(ctx); err != nil {
+		l.discardConn()
+		return err
+	}
+
+	return nil
+}
+
+func (l *postgresLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	if err != nil {
+		// La conexión no vuelve al pool con el lock tomado
+		l.discardConn()
+		return err
+	}
+	l.closeConn()
+
+	return nil
+}
+
+// closeConn devuelve la conexión al pool. Requiere l.mu.
+func (l *postgresLock) closeConn() {
+	if l.conn != nil {
+		_ = l.conn.Close()
+		l.conn = nil
+	}
+}
+
+// discardConn cierra la conexión sin devolverla al pool, lo que libera el lock
+// en PostgreSQL. Requiere l.mu.
+func (l *postgresLock) discardConn() {
+	if l.conn != nil {
+		_ = l.conn.Raw(func(driverConn interface{}) error { return driver.ErrBadConn })
+		l.closeConn()
+	}
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"strings"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+)
+
+// SchemaVersion versión del esquema JSON de los eventos. Se incrementa ante
+// cambios incompatibles; los campos nuevos opcionales no cambian la versión.
+const SchemaVersion = 1
+
+// Prefijos de los subjects: md.<symbol> y wallet.<id>
+const (
+	MarketDataSubject = "md"
+	WalletSubject     = "wallet"
+)
+
+// Publisher publica los precios para otros sistemas internos. Es un observer de
+// market data: OnMD no debe bloquear. Los eventos de wallets se entregan desde
+// el outbox (ver pkg/outbox).
+type Publisher interface {
+	OnMD(md model.MarketData)
+}
+
+// MarketDataEvent precio normalizado de un símbolo
+type MarketDataEvent struct {
+	Version   int             `json:"version"`
+	Symbol    string          `json:"symbol"`
+	Price     decimal.Decimal `json:"price"`
+	Timestamp time.Time       `json:"timestamp"`
+	Sources   []string        `json:"sources,omitempty"`
+}
+
+// WalletChangedEvent composición de una wallet luego de un cambio
+type WalletChangedEvent struct {
+	Version   int               `json:"version"`
+	WalletID  string            `json:"walletId"`
+	Items     []WalletItemEvent `json:"items"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+type WalletItemEvent struct {
+	Symbol   string          `json:"symbol"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+func NewMarketDataEvent(md model.MarketData) MarketDataEvent {
+	return MarketDataEvent{
+		Version:   SchemaVersion,
+		Symbol:    md.Symbol,
+		Price:     md.LastPrice,
+		Timestamp: md.LastPriceDateTime,
+		Sources:   md.Sources,
+	}
+}
+
+func NewWalletChangedEvent(wallet model.Wallet, timestamp time.Time) WalletChangedEvent {
+	items := make([]WalletItemEvent, 0, len(wallet.Items))
+	for _, item := range wallet.Items {
+		items = append(items, WalletItemEvent{Symbol: item.Symbol, Quantity: item.Quantity})
+	}
+
+	return WalletChangedEvent{
+		Version:   SchemaVersion,
+		WalletID:  wallet.ID,
+		Items:     items,
+		Timestamp: timestamp,
+	}
+}
+
+// Subject arma el subject prefix.token, reemplazando en token los caracteres
+// reservados por NATS
+func Subject(prefix, token string) string {
+	return prefix + "." + subjectReplacer.Replace(token)
+}
+
+// PrefixedSubject arma el subject [prefix.]kind.token
+func PrefixedSubject(prefix, kind, token string) string {
+	subject := Subject(kind, token)
+	if prefix != "" {
+		subject = prefix + "." + subject
+	}
+
+	return subject
+}
+
+var subjectReplacer = strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_", "\t", "_")
+
+// NopPublisher descarta los eventos
+type NopPublisher struct{}
+
+func (NopPublisher) OnMD(md model.MarketData) {}
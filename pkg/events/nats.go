@@ -0,0 +1,46 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+type natsPublisher struct {
+	logger *zap.Logger
+	conn   *nats.Conn
+	// prefix prefijo opcional de todos los subjects, por ejemplo el entorno
+	prefix string
+}
+
+// NewNATSPublisher publica los precios en JSON en los subjects
+// [prefix.]md.<symbol>. La conexión la administra quien la crea.
+func NewNATSPublisher(logger *zap.Logger, conn *nats.Conn, prefix string) Publisher {
+	return &natsPublisher{
+		logger: logger,
+		conn:   conn,
+		prefix: prefix,
+	}
+}
+
+// OnMD publica el precio. La librería de NATS encola el mensaje sin esperar al
+// servidor; si no hay conexión y se llenó el buffer de reconexión se descarta.
+func (p *natsPublisher) OnMD(md model.MarketData) {
+	subject := PrefixedSubject(p.prefix, MarketDataSubject, md.Symbol)
+
+	if err := p.publish(subject, NewMarketDataEvent(md)); err != nil {
+		p.logger.Error("error publishing market data event",
+			zap.String("subject", subject), zap.Error(err))
+	}
+}
+
+func (p *natsPublisher) publish(subject string, event interface{}) (err error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return p.conn.Publish(subject, data)
+}
@@ -0,0 +1,53 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// runNATSServer inicia un servidor NATS embebido en un puerto libre
+func runNATSServer(t *testing.T) *server.Server {
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1})
+	require.NoError(t, err)
+
+	go ns.Start()
+	require.True(t, ns.ReadyForConnections(5*time.Second))
+	t.Cleanup(ns.Shutdown)
+
+	return ns
+}
+
+func TestNATSPublisher(t *testing.T) {
+	ns := runNATSServer(t)
+
+	conn, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sub, err := conn.SubscribeSync("test.>")
+	require.NoError(t, err)
+
+	ts := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := NewNATSPublisher(zap.NewNop(), conn, "test")
+
+	p.OnMD(model.MarketData{
+		Symbol:            "BTCUSD",
+		LastPrice:         decimal.RequireFromString("45000.5"),
+		LastPriceDateTime: ts,
+		Sources:           []string{"cryptonator"},
+	})
+	msg, err := sub.NextMsg(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "test.md.BTCUSD", msg.Subject)
+	assert.JSONEq(t, `{"version":1,"symbol":"BTCUSD","price":"45000.5",
+		"timestamp":"2021-10-01T12:00:00Z","sources":["cryptonator"]}`, string(msg.Data))
+
+}
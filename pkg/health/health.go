@@ -0,0 +1,192 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp      = "up"
+	StatusDown    = "down"
+	StatusUnknown = "unknown"
+)
+
+// Check verificación activa del estado de un componente (ej. ping a la DB)
+type Check func(ctx context.Context) error
+
+// ComponentStatus estado de un componente, con su último éxito y su último error
+type ComponentStatus struct {
+	Name             string     `json:"name"`
+	Status           string     `json:"status"`
+	Critical         bool       `json:"critical"`
+	LastSuccess      *time.Time `json:"lastSuccess,omitempty"`
+	LastError        *time.Time `json:"lastError,omitempty"`
+	LastErrorMessage string     `json:"lastErrorMessage,omitempty"`
+}
+
+// Report estado general del servicio
+type Report struct {
+	Status     string            `json:"status"`
+	Components []ComponentStatus `json:"components"`
+}
+
+// Registry registro de componentes que informan su estado. Los componentes
+// críticos determinan si el servicio está listo para recibir tráfico.
+type Registry struct {
+	timeout time.Duration
+	now     func() time.Time
+
+	mu         sync.RWMutex
+	components map[string]*component
+}
+
+type component struct {
+	check            Check
+	critical         bool
+	lastSuccess      time.Time
+	lastError        time.Time
+	lastErrorMessage string
+}
+
+// Component permite a un componente informar pasivamente su estado
+type Component struct {
+	registry *Registry
+	name     string
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{
+		timeout:    timeout,
+		now:        time.Now,
+		components: make(map[string]*component),
+	}
+}
+
+// AddCheck registra una verificación que se ejecuta en cada consulta de readiness
+func (r *Registry) AddCheck(name string, check Check, critical bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.components[name] = &component{check: check, critical: critical}
+}
+
+// Component registra un componente que informa su estado con Success y Failure.
+// Mientras no informe nada su estado es desconocido.
+func (r *Registry) Component(name string, critical bool) *Component {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, found := r.components[name]; !found {
+		r.components[name] = &component{critical: critical}
+	}
+
+	return &Component{registry: r, name: name}
+}
+
+func (c *Component) Success() {
+	c.registry.record(c.name, nil)
+}
+
+func (c *Component) Failure(err error) {
+	c.registry.record(c.name, err)
+}
+
+func (r *Registry) record(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, found := r.components[name]
+	if !found {
+		return
+	}
+
+	if err != nil {
+		c.lastError = r.now()
+		c.lastErrorMessage = err.Error()
+		return
+	}
+
+	c.lastSuccess = r.now()
+}
+
+// Live indica si el proceso está vivo. Si puede responder, lo está.
+func (r *Registry) Live() bool {
+	return true
+}
+
+// Ready ejecuta las verificaciones y devuelve el estado de todos los
+// componentes. El servicio está listo si todos los componentes críticos lo están.
+func (r *Registry) Ready(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := map[string]Check{}
+	for name, c := range r.components {
+		if c.check != nil {
+			checks[name] = c.check
+		}
+	}
+	r.mu.RUnlock()
+
+	// Las verificaciones activas corren en paralelo, con timeout
+	wg := sync.WaitGroup{}
+	wg.Add(len(checks))
+	for name, check := range checks {
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+
+			r.record(name, check(checkCtx))
+		}(name, check)
+	}
+	wg.Wait()
+
+	return r.report()
+}
+
+func (r *Registry) report() Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Components: []ComponentStatus{}}
+
+	for name, c := range r.components {
+		status := ComponentStatus{
+			Name:             name,
+			Critical:         c.critical,
+			LastErrorMessage: c.lastErrorMessage,
+		}
+
+		switch {
+		case c.lastSuccess.IsZero() && c.lastError.IsZero():
+			status.Status = StatusUnknown
+		case c.lastError.After(c.lastSuccess):
+			status.Status = StatusDown
+		default:
+			status.Status = StatusUp
+		}
+
+		if !c.lastSuccess.IsZero() {
+			lastSuccess := c.lastSuccess
+			status.LastSuccess = &lastSuccess
+		}
+		if !c.lastError.IsZero() {
+			lastError := c.lastError
+			status.LastError = &lastError
+		}
+
+		if c.critical && status.Status != StatusUp {
+			report.Status = StatusDown
+		}
+
+		report.Components = append(report.Components, status)
+	}
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Name < report.Components[j].Name
+	})
+
+	return report
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryReadiness(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	registry := NewRegistry(time.Second)
+	registry.now = func() time.Time { return now }
+
+	dbErr := errors.New("connection refused")
+	registry.AddCheck("db", func(ctx context.Context) error { return dbErr }, true)
+	provider := registry.Component("provider.cryptonator", false)
+
+	report := registry.Ready(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "db", report.Components[0].Name)
+	assert.Equal(t, StatusDown, report.Components[0].Status)
+	assert.Equal(t, "connection refused", report.Components[0].LastErrorMessage)
+	assert.Equal(t, StatusUnknown, report.Components[1].Status)
+
+	// Los componentes no críticos no afectan la readiness
+	dbErr = nil
+	provider.Failure(errors.New("timeout"))
+	report = registry.Ready(context.Background())
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Equal(t, StatusUp, report.Components[0].Status)
+	assert.Equal(t, StatusDown, report.Components[1].Status)
+
+	now = now.Add(time.Minute)
+	provider.Success()
+	report = registry.Ready(context.Background())
+	assert.Equal(t, StatusUp, report.Components[1].Status)
+	assert.Equal(t, now, *report.Components[1].LastSuccess)
+	assert.Equal(t, now.Add(-time.Minute), *report.Components[1].LastError)
+	assert.Equal(t, "timeout", report.Components[1].LastErrorMessage)
+}
+
+func TestRegistryCheckTimeout(t *testing.T) {
+	registry := NewRegistry(10 * time.Millisecond)
+	registry.AddCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, true)
+
+	report := registry.Ready(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Components[0].LastErrorMessage)
+}
@@ -0,0 +1,379 @@
+package hub
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Policy qué hacer con una actualización cuando el buffer de un
+// suscriptor está lleno
+type Policy string
+
+const (
+	// DropPolicy descarta la actualización
+	DropPolicy Policy = "drop"
+	// ConflatePolicy conserva sólo la última actualización pendiente de cada
+	// símbolo, que se entrega cuando el suscriptor libera lugar
+	ConflatePolicy Policy = "conflate"
+	// DisconnectPolicy cierra la suscripción
+	DisconnectPolicy Policy = "disconnect"
+)
+
+// ErrSlowConsumer motivo del cierre de una suscripción con DisconnectPolicy
+var ErrSlowConsumer = errors.New("slow consumer disconnected")
+
+// ParsePolicy interpreta la política; vacío equivale a conflate
+func ParsePolicy(policy string) (Policy, error) {
+	switch Policy(policy) {
+	case "", ConflatePolicy:
+		return ConflatePolicy, nil
+	case DropPolicy, DisconnectPolicy:
+		return Policy(policy), nil
+	}
+
+	return "", fmt.Errorf("unknown slow consumer policy %s", policy)
+}
+
+// Hub distribuye las actualizaciones de market data a suscriptores, cada uno con
+// su propio buffer. Es un observer de market data: OnMD nunca bloquea, cuando el
+// buffer de un suscriptor está lleno se aplica la política.
+type Hub interface {
+	OnMD(md model.MarketData)
+	// Subscribe devuelve una suscripción a los símbolos indicados (sin símbolos, a
+	// todos)
+	Subscribe(symbols ...string) Subscription
+	// SubscribeAfter igual que Subscribe, pero el replay inicial incluye sólo los
+	// precios posteriores a lastSeq. Un lastSeq mayor al último emitido proviene
+	// de otro proceso, por lo que se envía el replay completo.
+	SubscribeAfter(lastSeq uint64, symbols ...string) Subscription
+}
+
+// Update actualización entregada a los suscriptores
+type Update struct {
+	// Seq crece con cada actualización publicada. Arranca en la hora de inicio en
+	// nanosegundos, por lo que también crece entre reinicios del proceso; no es
+	// comparable entre réplicas.
+	Seq uint64
+	model.MarketData
+}
+
+// Subscription suscripción a las actualizaciones de market data
+type Subscription interface {
+	// C recibe primero el último precio conocido de cada símbolo y luego cada
+	// actualización. Se cierra al desuscribirse o al desconectarse por lento.
+	C() <-chan Update
+	// Err devuelve ErrSlowConsumer si la suscripción se cerró por lenta
+	Err() error
+	Stats() Stats
+	Unsubscribe()
+}
+
+// Stats contadores de una suscripción
+type Stats struct {
+	ID        string
+	Delivered uint64
+	Dropped   uint64
+	Conflated uint64
+}
+
+type hubMetrics struct {
+	subscribers prometheus.Gauge
+	messages    *prometheus.CounterVec
+	disconnects prometheus.Counter
+}
+
+func newHubMetrics(registerer prometheus.Registerer) *hubMetrics {
+	m := &hubMetrics{
+		subscribers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "mtz_crypto_marketdata_subscribers",
+			Help: "Active market data subscriptions",
+		}),
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mtz_crypto_marketdata_subscriber_messages_total",
+			Help: "Market data updates per subscriber and result (delivered, dropped, conflated)",
+		}, []string{"subscriber", "result"}),
+		disconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mtz_crypto_marketdata_slow_consumer_disconnects_total",
+			Help: "Subscriptions closed for being slow",
+		}),
+	}
+
+	registerer.MustRegister(m.subscribers, m.messages, m.disconnects)
+
+	return m
+}
+
+type hub struct {
+	mu          sync.Mutex
+	bufferSize  int
+	policy      Policy
+	metrics     *hubMetrics
+	last        map[string]Update
+	seq         uint64
+	subscribers map[*subscription]bool
+	nextID      int
+}
+
+// NewHub crea el hub con buffers de bufferSize actualizaciones por suscriptor, y
+// registra las métricas de las suscripciones en registerer
+func NewHub(bufferSize int, policy Policy, registerer prometheus.Registerer) Hub {
+	return &hub{
+		bufferSize:  bufferSize,
+		policy:      policy,
+		metrics:     newHubMetrics(registerer),
+		last:        make(map[string]Update),
+		seq:         uint64(time.Now().UnixNano()),
+		subscribers: make(map[*subscription]bool),
+	}
+}
+
+// Subscribe registra la suscripción y le envía el último precio de cada símbolo
+func (h *hub) Subscribe(symbols ...string) Subscription {
+	return h.SubscribeAfter(0, symbols...)
+}
+
+func (h *hub) SubscribeAfter(lastSeq uint64, symbols ...string) Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	id := strconv.Itoa(h.nextID)
+
+	if lastSeq > h.seq {
+		lastSeq = 0
+	}
+
+	replay := []Update{}
+	if len(symbols) == 0 {
+		for _, update := range h.last {
+			if update.Seq > lastSeq {
+				replay = append(replay, update)
+			}
+		}
+		sort.Slice(replay, func(i, j int) bool { return replay[i].Seq < replay[j].Seq })
+	} else {
+		for _, symbol := range symbols {
+			if update, ok := h.last[symbol]; ok && update.Seq > lastSeq {
+				replay = append(replay, update)
+			}
+		}
+	}
+
+	// El buffer admite el replay completo, aunque supere bufferSize
+	s := &subscription{
+		hub:       h,
+		id:        id,
+		policy:    h.policy,
+		ch:        make(chan Update, h.bufferSize+len(replay)),
+		delivered: h.metrics.messages.WithLabelValues(id, "delivered"),
+		dropped:   h.metrics.messages.WithLabelValues(id, "dropped"),
+		conflated: h.metrics.messages.WithLabelValues(id, "conflated"),
+	}
+	if len(symbols) > 0 {
+		s.symbols = make(map[string]bool, len(symbols))
+		for _, symbol := range symbols {
+			s.symbols[symbol] = true
+		}
+	}
+	if s.policy == ConflatePolicy {
+		s.pending = make(map[string]Update)
+		s.signal = make(chan struct{}, 1)
+		s.done = make(chan struct{})
+		go s.pump()
+	}
+
+	h.subscribers[s] = true
+	h.metrics.subscribers.Inc()
+
+	for _, update := range replay {
+		s.offer(update)
+	}
+
+	return s
+}
+
+func (h *hub) OnMD(md model.MarketData) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	update := Update{Seq: h.seq, MarketData: md}
+	h.last[md.Symbol] = update
+
+	for s := range h.subscribers {
+		if s.symbols != nil && !s.symbols[md.Symbol] {
+			continue
+		}
+		if !s.offer(update) {
+			s.setErr(ErrSlowConsumer)
+			h.metrics.disconnects.Inc()
+			h.remove(s)
+		}
+	}
+}
+
+func (h *hub) unsubscribe(s *subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[s] {
+		h.remove(s)
+	}
+}
+
+// remove cierra la suscripción; se llama con el lock tomado
+func (h *hub) remove(s *subscription) {
+	delete(h.subscribers, s)
+	h.metrics.subscribers.Dec()
+	for _, result := range []string{"delivered", "dropped", "conflated"} {
+		h.metrics.messages.DeleteLabelValues(s.id, result)
+	}
+
+	if s.policy == ConflatePolicy {
+		// El pump es el único que envía al channel, y lo cierra al terminar
+		close(s.done)
+	} else {
+		close(s.ch)
+	}
+}
+
+type subscription struct {
+	hub     *hub
+	id      string
+	policy  Policy
+	symbols map[string]bool
+	ch      chan Update
+
+	// pendientes de entrega con ConflatePolicy, el último de cada símbolo
+	mu      sync.Mutex
+	pending map[string]Update
+	order   []string
+	signal  chan struct{}
+	done    chan struct{}
+	err     error
+
+	delivered, dropped, conflated    prometheus.Counter
+	deliveredN, droppedN, conflatedN uint64
+}
+
+func (s *subscription) C() <-chan Update {
+	return s.ch
+}
+
+func (s *subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+func (s *subscription) Stats() Stats {
+	return Stats{
+		ID:        s.id,
+		Delivered: atomic.LoadUint64(&s.deliveredN),
+		Dropped:   atomic.LoadUint64(&s.droppedN),
+		Conflated: atomic.LoadUint64(&s.conflatedN),
+	}
+}
+
+func (s *subscription) Unsubscribe() {
+	s.hub.unsubscribe(s)
+}
+
+func (s *subscription) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+// offer entrega la actualización sin bloquear. Devuelve false si hay que
+// desconectar al suscriptor.
+func (s *subscription) offer(update Update) bool {
+	if s.policy == ConflatePolicy {
+		s.mu.Lock()
+		if _, ok := s.pending[update.Symbol]; ok {
+			s.countConflated()
+		} else {
+			s.order = append(s.order, update.Symbol)
+		}
+		s.pending[update.Symbol] = update
+		s.mu.Unlock()
+
+		select {
+		case s.signal <- struct{}{}:
+		default:
+		}
+		return true
+	}
+
+	select {
+	case s.ch <- update:
+		s.countDelivered()
+		return true
+	default:
+	}
+
+	if s.policy == DisconnectPolicy {
+		return false
+	}
+
+	s.countDropped()
+	return true
+}
+
+// pump envía al channel las actualizaciones pendientes, en orden de llegada
+func (s *subscription) pump() {
+	defer close(s.ch)
+
+	for {
+		select {
+		case <-s.signal:
+		case <-s.done:
+			return
+		}
+
+		for {
+			s.mu.Lock()
+			if len(s.order) == 0 {
+				s.mu.Unlock()
+				break
+			}
+			symbol := s.order[0]
+			s.order = s.order[1:]
+			update := s.pending[symbol]
+			delete(s.pending, symbol)
+			s.mu.Unlock()
+
+			select {
+			case s.ch <- update:
+				s.countDelivered()
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+func (s *subscription) countDelivered() {
+	atomic.AddUint64(&s.deliveredN, 1)
+	s.delivered.Inc()
+}
+
+func (s *subscription) countDropped() {
+	atomic.AddUint64(&s.droppedN, 1)
+	s.dropped.Inc()
+}
+
+func (s *subscription) countConflated() {
+	atomic.AddUint64(&s.conflatedN, 1)
+	s.conflated.Inc()
+}
@@ -0,0 +1,173 @@
+package hub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func md(symbol, price string) model.MarketData {
+	return model.MarketData{Symbol: symbol, LastPrice: decimal.RequireFromString(price)}
+}
+
+// receive lee las actualizaciones disponibles hasta que no llegan más
+func receive(t *testing.T, sub Subscription) []string {
+	rs := []string{}
+	for {
+		select {
+		case md, ok := <-sub.C():
+			if !ok {
+				return rs
+			}
+			rs = append(rs, md.Symbol+"="+md.LastPrice.String())
+		case <-time.After(50 * time.Millisecond):
+			return rs
+		}
+	}
+}
+
+func TestHubReplayAndFilter(t *testing.T) {
+	h := NewHub(10, DropPolicy, prometheus.NewRegistry())
+	h.OnMD(md("BTCUSD", "45000"))
+	h.OnMD(md("ETHUSD", "3000"))
+	h.OnMD(md("BTCUSD", "45100"))
+
+	// Al suscribirse se recibe el último precio de cada símbolo
+	btc := h.Subscribe("BTCUSD", "ADAUSD")
+	all := h.Subscribe()
+	assert.Equal(t, []string{"BTCUSD=45100"}, receive(t, btc))
+	assert.ElementsMatch(t, []string{"BTCUSD=45100", "ETHUSD=3000"}, receive(t, all))
+
+	h.OnMD(md("ETHUSD", "3001"))
+	h.OnMD(md("ADAUSD", "2"))
+	assert.Equal(t, []string{"ADAUSD=2"}, receive(t, btc))
+	assert.Equal(t, []string{"ETHUSD=3001", "ADAUSD=2"}, receive(t, all))
+
+	// Al desuscribirse se cierra el channel
+	btc.Unsubscribe()
+	btc.Unsubscribe()
+	_, ok := <-btc.C()
+	assert.False(t, ok)
+	assert.NoError(t, btc.Err())
+}
+
+func TestHubDropPolicy(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	h := NewHub(2, DropPolicy, registry)
+	sub := h.Subscribe()
+
+	h.OnMD(md("BTCUSD", "1"))
+	h.OnMD(md("BTCUSD", "2"))
+	h.OnMD(md("BTCUSD", "3"))
+
+	assert.Equal(t, []string{"BTCUSD=1", "BTCUSD=2"}, receive(t, sub))
+	assert.Equal(t, Stats{ID: "1", Delivered: 2, Dropped: 1}, sub.Stats())
+	assert.Equal(t, 1.0, testutil.ToFloat64(h.(*hub).metrics.messages.WithLabelValues("1", "dropped")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(h.(*hub).metrics.subscribers))
+
+	sub.Unsubscribe()
+	assert.Equal(t, 0.0, testutil.ToFloat64(h.(*hub).metrics.subscribers))
+}
+
+func TestHubConflatePolicy(t *testing.T) {
+	h := NewHub(1, ConflatePolicy, prometheus.NewRegistry())
+	sub := h.Subscribe()
+
+	// Con el suscriptor lento sólo se conserva el último precio pendiente de cada
+	// símbolo. El pump retiene una actualización esperando lugar en el channel.
+	pending := func() int {
+		s := sub.(*subscription)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.order)
+	}
+	h.OnMD(md("BTCUSD", "1"))
+	require.Eventually(t, func() bool { return len(sub.C()) == 1 }, time.Second, time.Millisecond)
+	h.OnMD(md("BTCUSD", "2"))
+	require.Eventually(t, func() bool { return pending() == 0 }, time.Second, time.Millisecond)
+	h.OnMD(md("ETHUSD", "10"))
+	h.OnMD(md("BTCUSD", "3"))
+	h.OnMD(md("ETHUSD", "11"))
+	h.OnMD(md("BTCUSD", "4"))
+
+	assert.Equal(t, []string{"BTCUSD=1", "BTCUSD=2", "ETHUSD=11", "BTCUSD=4"}, receive(t, sub))
+	stats := sub.Stats()
+	assert.Equal(t, uint64(4), stats.Delivered)
+	assert.Equal(t, uint64(2), stats.Conflated)
+
+	sub.Unsubscribe()
+	_, ok := <-sub.C()
+	assert.False(t, ok)
+}
+
+func TestHubDisconnectPolicy(t *testing.T) {
+	h := NewHub(1, DisconnectPolicy, prometheus.NewRegistry())
+	slow := h.Subscribe("BTCUSD")
+	other := h.Subscribe("ETHUSD")
+
+	h.OnMD(md("BTCUSD", "1"))
+	h.OnMD(md("BTCUSD", "2"))
+	h.OnMD(md("ETHUSD", "10"))
+
+	assert.Equal(t, []string{"BTCUSD=1"}, receive(t, slow))
+	assert.ErrorIs(t, slow.Err(), ErrSlowConsumer)
+	assert.Equal(t, []string{"ETHUSD=10"}, receive(t, other))
+	assert.Equal(t, 1.0, testutil.ToFloat64(h.(*hub).metrics.disconnects))
+
+	// Desuscribirse luego de la desconexión no tiene efecto
+	slow.Unsubscribe()
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, ConflatePolicy, policy)
+
+	policy, err = ParsePolicy("disconnect")
+	assert.NoError(t, err)
+	assert.Equal(t, DisconnectPolicy, policy)
+
+	_, err = ParsePolicy("block")
+	assert.EqualError(t, err, "unknown slow consumer policy block")
+}
+
+func TestHubSeq(t *testing.T) {
+	h := NewHub(10, DropPolicy, prometheus.NewRegistry())
+	h.OnMD(md("BTCUSD", "1"))
+	h.OnMD(md("ETHUSD", "10"))
+	h.OnMD(md("BTCUSD", "2"))
+
+	// El replay de todos los símbolos respeta el orden de publicación
+	sub := h.Subscribe()
+	first, second := <-sub.C(), <-sub.C()
+	assert.Equal(t, "ETHUSD", first.Symbol)
+	assert.Equal(t, "BTCUSD", second.Symbol)
+	assert.Equal(t, first.Seq+1, second.Seq)
+	assert.Greater(t, first.Seq, uint64(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano()))
+}
+
+func TestHubSubscribeAfter(t *testing.T) {
+	h := NewHub(10, DropPolicy, prometheus.NewRegistry())
+	h.OnMD(md("BTCUSD", "1"))
+	first := <-h.Subscribe().C()
+	h.OnMD(md("ETHUSD", "10"))
+
+	// El replay omite lo recibido hasta lastSeq, pero no las actualizaciones
+	sub := h.SubscribeAfter(first.Seq + 1)
+	assert.Empty(t, receive(t, sub))
+	h.OnMD(md("BTCUSD", "2"))
+	assert.Equal(t, []string{"BTCUSD=2"}, receive(t, sub))
+
+	sub = h.SubscribeAfter(first.Seq, "BTCUSD", "ETHUSD")
+	assert.Equal(t, []string{"BTCUSD=2", "ETHUSD=10"}, receive(t, sub))
+
+	// Un lastSeq mayor al último emitido viene de otro proceso: replay completo
+	sub = h.SubscribeAfter(first.Seq + 100)
+	assert.Equal(t, []string{"ETHUSD=10", "BTCUSD=2"}, receive(t, sub))
+}
@@ -0,0 +1,84 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Group goroutines de un componente que se detienen juntas. El valor cero está
+// listo para usar. Luego de Stop se puede volver a usar, lo que permite reiniciar
+// el componente.
+type Group struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// context devuelve el contexto de la ejecución actual. Requiere g.mu.
+func (g *Group) context() context.Context {
+	if g.ctx == nil {
+		g.ctx, g.cancel = context.WithCancel(context.Background())
+	}
+
+	return g.ctx
+}
+
+// Go ejecuta fn en una goroutine. El contexto recibido se cancela en Stop.
+func (g *Group) Go(fn func(ctx context.Context)) {
+	g.mu.Lock()
+	ctx := g.context()
+	g.wg.Add(1)
+	g.mu.Unlock()
+
+	go func() {
+		defer g.wg.Done()
+		fn(ctx)
+	}()
+}
+
+// Context contexto que se cancela en Stop
+func (g *Group) Context() context.Context {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.context()
+}
+
+// Stop cancela el contexto de las goroutines y espera a que terminen, como
+// máximo hasta que venza ctx
+func (g *Group) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.ctx, g.cancel = nil, nil
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sleep espera d o hasta que se cancele ctx. Devuelve false si se canceló.
+func Sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,186 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Component componente con goroutines o recursos a liberar. Start no debe
+// bloquear: inicia el trabajo en segundo plano y devuelve. Stop detiene el
+// trabajo y libera los recursos antes de que venza el contexto.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Hook adapta funciones sueltas a Component. Las funciones nil no hacen nada.
+type Hook struct {
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+func (h Hook) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+
+	return h.OnStart(ctx)
+}
+
+func (h Hook) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+
+	return h.OnStop(ctx)
+}
+
+// Manager inicia los componentes en orden de dependencias y los detiene en el
+// orden inverso
+type Manager struct {
+	logger *zap.Logger
+
+	mu         sync.Mutex
+	components map[string]*entry
+	order      []string
+	started    []*entry
+}
+
+type entry struct {
+	name      string
+	component Component
+	dependsOn []string
+}
+
+func NewManager(logger *zap.Logger) *Manager {
+	return &Manager{
+		logger:     logger,
+		components: make(map[string]*entry),
+	}
+}
+
+// Add registra un componente. Las dependencias se inician antes y se detienen
+// después que el componente.
+func (m *Manager) Add(name string, component Component, dependsOn ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, found := m.components[name]; !found {
+		m.order = append(m.order, name)
+	}
+	m.components[name] = &entry{name: name, component: component, dependsOn: dependsOn}
+}
+
+// Start inicia los componentes en orden de dependencias. Si uno falla se
+// detienen los ya iniciados y se devuelve el error.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sorted, err := m.sort()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range sorted {
+		m.logger.Debug("starting component", zap.String("component", e.name))
+
+		if err := e.component.Start(ctx); err != nil {
+			err = fmt.Errorf("starting %s: %w", e.name, err)
+			m.logger.Error("error starting component", zap.String("component", e.name), zap.Error(err))
+
+			if stopErr := m.stop(ctx); stopErr != nil {
+				return fmt.Errorf("%w; %s", err, stopErr)
+			}
+			return err
+		}
+
+		m.started = append(m.started, e)
+	}
+
+	m.logger.Info("all components started", zap.Int("components", len(m.started)))
+
+	return nil
+}
+
+// Stop detiene los componentes iniciados en orden inverso. Se detienen todos
+// aunque alguno falle o venza el contexto; se devuelven todos los errores.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stop(ctx)
+}
+
+// stop detiene los componentes iniciados. Requiere m.mu.
+func (m *Manager) stop(ctx context.Context) error {
+	errs := []string{}
+	for i := len(m.started) - 1; i >= 0; i-- {
+		e := m.started[i]
+		m.logger.Debug("stopping component", zap.String("component", e.name))
+
+		if err := e.component.Stop(ctx); err != nil {
+			m.logger.Error("error stopping component", zap.String("component", e.name), zap.Error(err))
+			errs = append(errs, fmt.Sprintf("stopping %s: %s", e.name, err))
+		}
+	}
+	m.started = nil
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	m.logger.Info("all components stopped")
+
+	return nil
+}
+
+// sort ordena los componentes de modo que cada uno quede después de sus
+// dependencias, respetando el orden de registro. Requiere m.mu.
+func (m *Manager) sort() ([]*entry, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := map[string]int{}
+	sorted := make([]*entry, 0, len(m.order))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		e, found := m.components[name]
+		if !found {
+			return fmt.Errorf("unknown component %s (required by %s)", name, path[len(path)-1])
+		}
+
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s -> %s", strings.Join(path, " -> "), name)
+		}
+
+		state[name] = visiting
+		for _, dep := range e.dependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		sorted = append(sorted, e)
+
+		return nil
+	}
+
+	for _, name := range m.order {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,119 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func recorder(events *[]string, name string, startErr, stopErr error) Hook {
+	return Hook{
+		OnStart: func(ctx context.Context) error {
+			*events = append(*events, "start "+name)
+			return startErr
+		},
+		OnStop: func(ctx context.Context) error {
+			*events = append(*events, "stop "+name)
+			return stopErr
+		},
+	}
+}
+
+func TestManagerDependencyOrder(t *testing.T) {
+	events := []string{}
+	m := NewManager(zap.NewNop())
+	m.Add("http", recorder(&events, "http", nil, nil), "service", "db")
+	m.Add("service", recorder(&events, "service", nil, nil), "db")
+	m.Add("db", recorder(&events, "db", nil, nil))
+
+	assert.NoError(t, m.Start(context.Background()))
+	assert.NoError(t, m.Stop(context.Background()))
+
+	assert.Equal(t, []string{
+		"start db", "start service", "start http",
+		"stop http", "stop service", "stop db",
+	}, events)
+}
+
+func TestManagerStartFailureStopsStarted(t *testing.T) {
+	events := []string{}
+	m := NewManager(zap.NewNop())
+	m.Add("db", recorder(&events, "db", nil, nil))
+	m.Add("service", recorder(&events, "service", errors.New("boom"), nil), "db")
+	m.Add("http", recorder(&events, "http", nil, nil), "service")
+
+	err := m.Start(context.Background())
+	assert.EqualError(t, err, "starting service: boom")
+	assert.Equal(t, []string{"start db", "start service", "stop db"}, events)
+}
+
+func TestManagerStopReportsAllErrors(t *testing.T) {
+	events := []string{}
+	m := NewManager(zap.NewNop())
+	m.Add("db", recorder(&events, "db", nil, errors.New("close failed")))
+	m.Add("provider", recorder(&events, "provider", nil, context.DeadlineExceeded), "db")
+
+	assert.NoError(t, m.Start(context.Background()))
+	err := m.Stop(context.Background())
+	assert.EqualError(t, err, "stopping provider: context deadline exceeded; stopping db: close failed")
+	assert.Equal(t, []string{"start db", "start provider", "stop provider", "stop db"}, events)
+}
+
+func TestManagerInvalidDependencies(t *testing.T) {
+	m := NewManager(zap.NewNop())
+	m.Add("a", Hook{}, "b")
+	assert.EqualError(t, m.Start(context.Background()), "unknown component b (required by a)")
+
+	m.Add("b", Hook{}, "a")
+	assert.EqualError(t, m.Start(context.Background()), "dependency cycle: a -> b -> a")
+}
+
+func TestGroupStop(t *testing.T) {
+	var g Group
+	stopped := make(chan struct{})
+	g.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	assert.NoError(t, g.Stop(context.Background()))
+	<-stopped
+
+	// Una goroutine que no termina agota el timeout
+	var stuck Group
+	block := make(chan struct{})
+	defer close(block)
+	stuck.Go(func(ctx context.Context) { <-block })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, stuck.Stop(ctx), context.DeadlineExceeded)
+}
+
+func TestGroupRestart(t *testing.T) {
+	var g Group
+	g.Go(func(ctx context.Context) { <-ctx.Done() })
+	assert.NoError(t, g.Stop(context.Background()))
+
+	// Luego de Stop las nuevas goroutines reciben un contexto vigente
+	running := make(chan struct{})
+	g.Go(func(ctx context.Context) {
+		close(running)
+		<-ctx.Done()
+	})
+	<-running
+	assert.NoError(t, g.Context().Err())
+	assert.NoError(t, g.Stop(context.Background()))
+}
+
+func TestSleep(t *testing.T) {
+	assert.True(t, Sleep(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, Sleep(ctx, time.Hour))
+}
@@ -11,6 +11,54 @@ type MarketData struct {
 	Symbol            string
 	LastPrice         decimal.Decimal
 	LastPriceDateTime time.Time
+	// Sources proveedores que originaron el precio
+	Sources []string
+}
+
+// Tipos de instrumentos derivados
+const (
+	// DerivedCross cociente entre dos símbolos, ej. ETHBTC = ETHUSD / BTCUSD
+	DerivedCross = "cross"
+	// DerivedInverse inversa de un símbolo, ej. USDBTC = 1 / BTCUSD
+	DerivedInverse = "inverse"
+	// DerivedProduct producto de símbolos, ej. BTCARS = BTCUSD * USDARS
+	DerivedProduct = "product"
+)
+
+// DerivedInstrument instrumento cuyo precio se calcula a partir de otros símbolos (legs)
+type DerivedInstrument struct {
+	Symbol string
+	Kind   string
+	Legs   []string
+}
+
+// Index índice de canasta que se publica como símbolo sintético
+type Index struct {
+	Symbol string
+	// Base nivel inicial, usado para convertir ponderaciones en unidades
+	Base         decimal.Decimal
+	Compositions []IndexComposition
+}
+
+// IndexComposition composición del índice vigente desde la fecha de rebalanceo
+type IndexComposition struct {
+	Effective    time.Time
+	Divisor      decimal.Decimal
+	Constituents []IndexConstituent
+}
+
+// IndexConstituent componente del índice, definido por ponderación o por unidades
+type IndexConstituent struct {
+	Symbol    string              `json:"symbol"`
+	Weight    decimal.NullDecimal `json:"weight"`
+	Units     decimal.NullDecimal `json:"units"`
+	LastPrice decimal.NullDecimal `json:"lastPrice"`
+}
+
+// IndexLevel valor del índice en un momento dado
+type IndexLevel struct {
+	Level    decimal.Decimal `json:"level"`
+	DateTime time.Time       `json:"dateTime"`
 }
 
 type Wallet struct {
@@ -23,6 +71,30 @@ type WalletItem struct {
 	Quantity decimal.Decimal
 }
 
+// PositionChange cambio de posición de una wallet informado por back-office. Se
+// indica la cantidad final (Quantity) o la variación (Delta), no ambas. Con
+// cantidad final 0 el símbolo se quita de la wallet.
+type PositionChange struct {
+	// MessageID identifica el mensaje; un mensaje repetido no se vuelve a aplicar
+	MessageID string              `json:"id"`
+	WalletID  string              `json:"walletId"`
+	Symbol    string              `json:"symbol"`
+	Quantity  decimal.NullDecimal `json:"quantity"`
+	Delta     decimal.NullDecimal `json:"delta"`
+}
+
+// OutboxEvent evento de cambio de una wallet pendiente de entrega. Se escribe en
+// la misma transacción que el cambio, por lo que no se pierde si el proceso
+// termina antes de publicarlo.
+type OutboxEvent struct {
+	ID       int64
+	WalletID string
+	// Payload evento en JSON, listo para entregar
+	Payload   []byte
+	Attempts  int
+	CreatedAt time.Time
+}
+
 type GetWalletValueRequest struct {
 	ID string
 }
@@ -33,10 +105,123 @@ type GetWalletValueResponse struct {
 	DateTime *time.Time          `json:"dateTime,omitempty"`
 }
 
+// WalletValueUpdate valuación de una wallet observada, enviada por WebSocket
+type WalletValueUpdate struct {
+	GetWalletValueResponse
+	Error string `json:"error,omitempty"`
+}
+
+// WalletLiveRequest mensaje del cliente para agregar o quitar wallets observadas
+type WalletLiveRequest struct {
+	Subscribe   []string `json:"subscribe"`
+	Unsubscribe []string `json:"unsubscribe"`
+}
+
+type GetIndexResponse struct {
+	Symbol        string              `json:"symbol"`
+	Level         decimal.NullDecimal `json:"level"`
+	DateTime      *time.Time          `json:"dateTime,omitempty"`
+	Divisor       decimal.Decimal     `json:"divisor"`
+	Effective     *time.Time          `json:"effective,omitempty"`
+	NextRebalance *time.Time          `json:"nextRebalance,omitempty"`
+	Constituents  []IndexConstituent  `json:"constituents"`
+}
+
+type GetIndexHistoryResponse struct {
+	Symbol  string       `json:"symbol"`
+	History []IndexLevel `json:"history"`
+}
+
+// Estados de actualización de un símbolo
+const (
+	SymbolStatusFresh   = "fresh"
+	SymbolStatusStale   = "stale"
+	SymbolStatusUnknown = "unknown"
+)
+
+// Eventos de frescura informados a operaciones
+const (
+	FreshnessEventStale     = "stale"
+	FreshnessEventRecovered = "recovered"
+)
+
+// SymbolStatus estado de actualización de la market data de un símbolo
+type SymbolStatus struct {
+	Symbol              string     `json:"symbol"`
+	Status              string     `json:"status"`
+	LastUpdate          *time.Time `json:"lastUpdate,omitempty"`
+	AgeSeconds          float64    `json:"ageSeconds"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	LastErrorDateTime   *time.Time `json:"lastErrorDateTime,omitempty"`
+	Gaps                int        `json:"gaps"`
+	LastGapSeconds      float64    `json:"lastGapSeconds"`
+	MaxGapSeconds       float64    `json:"maxGapSeconds"`
+}
+
+// MarketDataResponse último precio de un símbolo
+type MarketDataResponse struct {
+	Symbol   string          `json:"symbol"`
+	Price    decimal.Decimal `json:"price"`
+	DateTime time.Time       `json:"dateTime"`
+	Sources  []string        `json:"sources"`
+}
+
+type GetMarketDataStatusResponse struct {
+	StaleThresholdSeconds float64        `json:"staleThresholdSeconds"`
+	Symbols               []SymbolStatus `json:"symbols"`
+}
+
+// FreshnessEvent notificación de un símbolo que dejó de actualizarse o que se recuperó
+type FreshnessEvent struct {
+	Event    string       `json:"event"`
+	DateTime time.Time    `json:"dateTime"`
+	Status   SymbolStatus `json:"status"`
+}
+
+// Subscription par consultado por un proveedor, administrable en runtime
+type Subscription struct {
+	Provider       string    `json:"provider"`
+	Symbol         string    `json:"symbol"`
+	ExternalSymbol string    `json:"externalSymbol"`
+	Paused         bool      `json:"paused"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	// RemovedAt fecha de baja, si se eliminó en runtime
+	RemovedAt *time.Time `json:"-"`
+}
+
+type AddSubscriptionRequest struct {
+	Symbol         string `json:"symbol"`
+	ExternalSymbol string `json:"externalSymbol"`
+}
+
+type GetSubscriptionsResponse struct {
+	Provider      string         `json:"provider"`
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
 type MdChannel chan MarketData
 
 var (
 	// TODO agregar el resto de los errores
 	ErrWalletIsRequired = errors.New("wallet is required")
 	ErrUnexpected       = errors.New("unexpected error")
+	ErrIndexNotFound    = errors.New("index not found")
+	ErrInvalidLimit     = errors.New("invalid limit")
+
+	ErrProviderNotFound     = errors.New("provider not found or does not support subscriptions")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrSubscriptionExists   = errors.New("subscription already exists")
+	ErrInvalidSubscription  = errors.New("symbol and externalSymbol are required")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrAdminDisabled        = errors.New("admin token not configured")
+
+	ErrInvalidPositionChange = errors.New("position change requires id, walletId, symbol and either quantity or delta")
+	ErrNegativePosition      = errors.New("position change results in a negative quantity")
+
+	ErrInvalidLastEventID = errors.New("invalid Last-Event-ID")
+
+	ErrSymbolNotFound = errors.New("symbol not found")
+	ErrInvalidFormat  = errors.New("format must be json or csv")
 )
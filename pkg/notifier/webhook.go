@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Notifier envía notificaciones a sistemas externos
+type Notifier interface {
+	Notify(ctx context.Context, payload interface{}) (err error)
+}
+
+type webhookNotifier struct {
+	httpClient *http.Client
+	url        string
+}
+
+// NewWebhookNotifier envía cada notificación como un POST con el payload en JSON
+func NewWebhookNotifier(httpClient *http.Client, url string) Notifier {
+	return &webhookNotifier{
+		httpClient: httpClient,
+		url:        url,
+	}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, payload interface{}) (err error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// NopNotifier descarta las notificaciones
+type NopNotifier struct{}
+
+func (NopNotifier) Notify(ctx context.Context, payload interface{}) (err error) {
+	return nil
+}
@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&received))
+
+		if received["symbol"] == "FAIL" {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.Client(), server.URL)
+
+	err := n.Notify(context.Background(), map[string]string{"symbol": "BTCUSD"})
+	assert.NoError(t, err)
+	assert.Equal(t, "BTCUSD", received["symbol"])
+
+	err = n.Notify(context.Background(), map[string]string{"symbol": "FAIL"})
+	assert.EqualError(t, err, "webhook responded with status 500")
+}
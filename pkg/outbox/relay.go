@@ -0,0 +1,155 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"go.uber.org/zap"
+)
+
+// deliverTimeout tiempo máximo de entrega de cada evento
+const deliverTimeout = 10 * time.Second
+
+// claimLease tiempo durante el que un evento leído queda reservado para esta
+// réplica. Debe superar a deliverTimeout; luego de un error es la espera antes
+// del reintento.
+const claimLease = 3 * deliverTimeout
+
+// Sink destino de los eventos del outbox. Un evento puede entregarse más de una
+// vez (por ejemplo, si el proceso termina luego de entregarlo y antes de
+// eliminarlo del outbox).
+type Sink interface {
+	Deliver(ctx context.Context, event model.OutboxEvent) (err error)
+}
+
+// Relay entrega periódicamente los eventos pendientes del outbox al sink
+type Relay interface {
+	lifecycle.Component
+}
+
+type relay struct {
+	logger      *zap.Logger
+	store       store.OutboxStore
+	sink        Sink
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	routines    lifecycle.Group
+}
+
+func NewRelay(
+	logger *zap.Logger,
+	outboxStore store.OutboxStore,
+	sink Sink,
+	interval time.Duration,
+	batchSize int,
+	maxAttempts int,
+) Relay {
+	return &relay{
+		logger:      logger,
+		store:       outboxStore,
+		sink:        sink,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (r *relay) Start(ctx context.Context) error {
+	r.routines.Go(func(ctx context.Context) {
+		for {
+			// Si se entregaron eventos puede haber más pendientes de esas wallets:
+			// se sigue sin esperar
+			delivered := r.deliverPending(ctx)
+			if delivered > 0 && ctx.Err() == nil {
+				continue
+			}
+			if !lifecycle.Sleep(ctx, r.interval) {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (r *relay) Stop(ctx context.Context) error {
+	return r.routines.Stop(ctx)
+}
+
+// deliverPending entrega los eventos reservados. Son de wallets distintas, por
+// lo que se entregan en paralelo y el lote tarda a lo sumo deliverTimeout.
+func (r *relay) deliverPending(ctx context.Context) int {
+	events, err := r.store.ClaimPending(r.batchSize, claimLease)
+	if err != nil {
+		r.logger.Error("error reading outbox", zap.Error(err))
+		return 0
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+	)
+	for _, event := range events {
+		wg.Add(1)
+		go func(event model.OutboxEvent) {
+			defer wg.Done()
+
+			if r.deliver(ctx, event) {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}(event)
+	}
+	wg.Wait()
+
+	return delivered
+}
+
+func (r *relay) deliver(ctx context.Context, event model.OutboxEvent) bool {
+	deliverCtx, cancel := context.WithTimeout(ctx, deliverTimeout)
+	defer cancel()
+
+	err := r.sink.Deliver(deliverCtx, event)
+	if err == nil {
+		if err := r.store.Delete(event.ID); err != nil {
+			// Se vuelve a entregar al vencer el lease
+			r.logger.Error("error deleting delivered outbox event", zap.Int64("id", event.ID), zap.Error(err))
+			return false
+		}
+		return true
+	}
+
+	if ctx.Err() != nil {
+		// Al detenerse no se cuenta el intento; se reintenta al vencer el lease
+		return false
+	}
+
+	attempts := event.Attempts + 1
+	dead := r.maxAttempts > 0 && attempts >= r.maxAttempts
+	if dead {
+		r.logger.Error("outbox event discarded after max attempts, wallet events are blocked until it is removed",
+			zap.Int64("id", event.ID),
+			zap.String("walletId", event.WalletID),
+			zap.Int("attempts", attempts),
+			zap.Error(err))
+	} else {
+		r.logger.Warn("error delivering outbox event, will retry",
+			zap.Int64("id", event.ID),
+			zap.String("walletId", event.WalletID),
+			zap.Int("attempts", attempts),
+			zap.Error(err))
+	}
+
+	if err := r.store.MarkFailed(event.ID, err.Error(), dead); err != nil {
+		r.logger.Error("error recording outbox delivery failure", zap.Int64("id", event.ID), zap.Error(err))
+	}
+
+	return false
+}
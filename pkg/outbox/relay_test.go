@@ -0,0 +1,75 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeSink struct {
+	mu        sync.Mutex
+	delivered []int64
+}
+
+func (s *fakeSink) Deliver(ctx context.Context, event model.OutboxEvent) (err error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("context without deadline")
+	}
+	if event.WalletID == "failing" {
+		return errors.New("sink down")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delivered = append(s.delivered, event.ID)
+
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	storeMock := new(mocks.OutboxStore)
+	sink := &fakeSink{}
+	drained := make(chan struct{})
+
+	// Mientras se entreguen eventos se vuelve a leer sin esperar el intervalo
+	storeMock.On("ClaimPending", 2, claimLease).Return([]model.OutboxEvent{
+		{ID: 1, WalletID: "wallet1"},
+		{ID: 2, WalletID: "failing"},
+	}, nil).Once()
+	storeMock.On("ClaimPending", 2, claimLease).Return([]model.OutboxEvent{
+		{ID: 3, WalletID: "wallet1"},
+		{ID: 4, WalletID: "failing", Attempts: 2},
+	}, nil).Once()
+	storeMock.On("ClaimPending", 2, claimLease).Return(nil, nil).Once().Run(func(args mock.Arguments) {
+		close(drained)
+	})
+	storeMock.On("Delete", int64(1)).Return(nil).Once()
+	storeMock.On("Delete", int64(3)).Return(nil).Once()
+	// Al llegar al máximo de intentos el evento se descarta
+	storeMock.On("MarkFailed", int64(2), "sink down", false).Return(nil).Once()
+	storeMock.On("MarkFailed", int64(4), "sink down", true).Return(nil).Once()
+
+	r := NewRelay(zap.NewNop(), storeMock, sink, time.Hour, 2, 3)
+	require.NoError(t, r.Start(context.Background()))
+
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("outbox not drained")
+	}
+	require.NoError(t, r.Stop(context.Background()))
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	assert.ElementsMatch(t, []int64{1, 3}, sink.delivered)
+	storeMock.AssertExpectations(t)
+}
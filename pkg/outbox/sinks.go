@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/matbarofex/mtz-crypto/pkg/events"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/notifier"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+type webhookSink struct {
+	notifier notifier.Notifier
+}
+
+// NewWebhookSink envía cada evento como un POST con el evento en el body
+func NewWebhookSink(notifier notifier.Notifier) Sink {
+	return &webhookSink{notifier: notifier}
+}
+
+func (s *webhookSink) Deliver(ctx context.Context, event model.OutboxEvent) (err error) {
+	return s.notifier.Notify(ctx, json.RawMessage(event.Payload))
+}
+
+type natsSink struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSSink publica cada evento en el subject [prefix.]wallet.<id> y espera a
+// que el servidor lo reciba. La conexión la administra quien la crea.
+func NewNATSSink(conn *nats.Conn, prefix string) Sink {
+	return &natsSink{conn: conn, prefix: prefix}
+}
+
+func (s *natsSink) Deliver(ctx context.Context, event model.OutboxEvent) (err error) {
+	subject := events.PrefixedSubject(s.prefix, events.WalletSubject, event.WalletID)
+	if err := s.conn.Publish(subject, event.Payload); err != nil {
+		return err
+	}
+
+	return s.conn.FlushWithContext(ctx)
+}
+
+type logSink struct {
+	logger *zap.Logger
+}
+
+// NewLogSink registra cada evento en el log
+func NewLogSink(logger *zap.Logger) Sink {
+	return &logSink{logger: logger}
+}
+
+func (s *logSink) Deliver(ctx context.Context, event model.OutboxEvent) (err error) {
+	s.logger.Info("wallet changed",
+		zap.String("walletId", event.WalletID),
+		zap.ByteString("event", event.Payload))
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/notifier"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var event = model.OutboxEvent{
+	ID:       1,
+	WalletID: "wallet.1",
+	Payload:  []byte(`{"version":1,"walletId":"wallet.1","items":[],"timestamp":"2021-10-01T12:00:00Z"}`),
+}
+
+func TestWebhookSink(t *testing.T) {
+	status := http.StatusOK
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received, _ = io.ReadAll(req.Body)
+		rw.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(notifier.NewWebhookNotifier(server.Client(), server.URL))
+
+	require.NoError(t, sink.Deliver(context.Background(), event))
+	assert.JSONEq(t, string(event.Payload), string(received))
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, sink.Deliver(context.Background(), event))
+}
+
+func TestNATSSink(t *testing.T) {
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1})
+	require.NoError(t, err)
+	go ns.Start()
+	require.True(t, ns.ReadyForConnections(5*time.Second))
+	defer ns.Shutdown()
+
+	conn, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sub, err := conn.SubscribeSync("test.wallet.>")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, NewNATSSink(conn, "test").Deliver(ctx, event))
+
+	msg, err := sub.NextMsg(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "test.wallet.wallet_1", msg.Subject)
+	assert.Equal(t, event.Payload, msg.Data)
+}
@@ -0,0 +1,114 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Estados del circuit breaker
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// ErrCircuitOpen el circuito está abierto y no se realizan llamadas
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker deja de llamar a un proveedor luego de varias fallas
+// consecutivas. Pasado openTimeout permite una llamada de prueba (half-open):
+// si funciona se cierra el circuito, si falla se vuelve a abrir.
+type CircuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker crea un circuit breaker. Con failureThreshold <= 0 el
+// circuito nunca se abre.
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+		state:            StateClosed,
+	}
+}
+
+// Do ejecuta fn si el circuito lo permite. Los errores permanentes no cuentan
+// como fallas, salvo los rechazos por límite de consultas. Con el circuito
+// abierto devuelve ErrCircuitOpen como error permanente.
+func (b *CircuitBreaker) Do(fn func() error) error {
+	if !b.allow() {
+		return Permanent(ErrCircuitOpen)
+	}
+
+	err := fn()
+	if err != nil && (!IsPermanent(err) || IsThrottled(err)) {
+		b.failure()
+	} else {
+		b.success()
+	}
+
+	return err
+}
+
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		// Sólo una llamada de prueba a la vez
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *CircuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+
+	if b.state == StateHalfOpen || (b.failureThreshold > 0 && b.failures >= b.failureThreshold) {
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+}
@@ -0,0 +1,73 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	failing := func() error { return errors.New("503") }
+	calls := 0
+	counting := func() error { calls++; return nil }
+
+	// Los errores permanentes no abren el circuito
+	assert.Error(t, b.Do(func() error { return Permanent(errors.New("pair not found")) }))
+	assert.Error(t, b.Do(failing))
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.Error(t, b.Do(failing))
+	assert.Equal(t, StateOpen, b.State())
+
+	// Abierto: no se llama al proveedor
+	err := b.Do(counting)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.True(t, IsPermanent(err))
+	assert.Equal(t, 0, calls)
+
+	// Pasado el timeout la llamada de prueba falla y vuelve a abrirse
+	now = now.Add(time.Minute)
+	assert.Error(t, b.Do(failing))
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.Do(counting), ErrCircuitOpen)
+
+	// La llamada de prueba funciona y se cierra
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Do(counting))
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, 1, calls)
+}
+
+func TestCircuitBreakerThrottled(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	throttled := func() error { return Throttled(errors.New("429")) }
+
+	// El límite de consultas no se reintenta pero cuenta como falla
+	err := b.Do(throttled)
+	assert.True(t, IsPermanent(err))
+	assert.True(t, IsThrottled(err))
+	assert.Equal(t, StateOpen, b.State())
+
+	// La llamada de prueba limitada no cierra el circuito
+	now = now.Add(time.Minute)
+	assert.Error(t, b.Do(throttled))
+	assert.Equal(t, StateOpen, b.State())
+	assert.False(t, IsThrottled(Permanent(errors.New("404"))))
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := NewCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		assert.Error(t, b.Do(func() error { return errors.New("503") }))
+	}
+	assert.Equal(t, StateClosed, b.State())
+}
@@ -0,0 +1,125 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy política de reintentos con backoff exponencial y jitter
+type RetryPolicy struct {
+	// MaxAttempts cantidad máxima de intentos, incluido el primero
+	MaxAttempts int
+	MinDelay    time.Duration
+	MaxDelay    time.Duration
+}
+
+// permanentError error que no debe reintentarse
+type permanentError struct {
+	err       error
+	throttled bool
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marca un error para que no se reintente ni cuente como falla del
+// proveedor (ej. símbolo inexistente)
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// Throttled marca un rechazo por límite de consultas (ej. HTTP 429): no se
+// reintenta, pero cuenta como falla del proveedor
+func Throttled(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err, throttled: true}
+}
+
+// IsPermanent indica si el error fue marcado como permanente
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// IsThrottled indica si el error fue marcado como límite de consultas
+func IsThrottled(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent) && permanent.throttled
+}
+
+// Retry ejecuta fn hasta que no devuelva error, devuelva un error permanente,
+// se agoten los intentos o se cancele el contexto. Devuelve el último error.
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) (err error) {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(Backoff(policy.MinDelay, policy.MaxDelay, attempt-1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+
+		err = fn()
+		if err == nil || IsPermanent(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// Backoff calcula la espera antes de un reintento: exponencial, acotada y con
+// jitter sobre la mitad del valor para que las réplicas no reintenten a la vez
+func Backoff(minDelay, maxDelay time.Duration, attempt int) time.Duration {
+	delay := minDelay
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+
+	return time.Duration(half + rand.Int63n(half))
+}
+
+// RetryAfter interpreta el header Retry-After, en segundos o como fecha HTTP.
+// Si no está presente se espera un minuto.
+func RetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return time.Minute
+}
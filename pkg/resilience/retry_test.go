@@ -0,0 +1,72 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPolicy = RetryPolicy{MaxAttempts: 3, MinDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestRetrySucceedsAfterFailures(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), testPolicy, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryIsBounded(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), testPolicy, func() error {
+		calls++
+		return errors.New("timeout")
+	})
+
+	assert.EqualError(t, err, "timeout")
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryStopsOnPermanentError(t *testing.T) {
+	notFound := errors.New("pair not found")
+	calls := 0
+	err := Retry(context.Background(), testPolicy, func() error {
+		calls++
+		return Permanent(notFound)
+	})
+
+	assert.ErrorIs(t, err, notFound)
+	assert.True(t, IsPermanent(err))
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryStopsOnCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 5, MinDelay: time.Second, MaxDelay: time.Second}, func() error {
+		calls++
+		return errors.New("timeout")
+	})
+
+	assert.EqualError(t, err, "timeout")
+	assert.Equal(t, 1, calls)
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := Backoff(100*time.Millisecond, time.Second, attempt)
+		assert.LessOrEqual(t, delay, time.Second)
+		assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+)
+
+// SymbolDemand cuenta cuántas veces las wallets referencian cada símbolo. Un
+// símbolo es caliente si sus referencias en la última ventana alcanzan el
+// umbral; los proveedores lo consultan con más frecuencia.
+type SymbolDemand interface {
+	crypto.Demand
+	Reference(symbols ...string)
+}
+
+type symbolDemand struct {
+	window    time.Duration
+	threshold float64
+	now       func() time.Time
+
+	mu sync.Mutex
+	// Ventana deslizante aproximada con la ventana actual y la anterior
+	windowStart time.Time
+	current     map[string]int
+	previous    map[string]int
+}
+
+// NewSymbolDemand crea el contador de demanda. Con threshold <= 0 ningún
+// símbolo es caliente.
+func NewSymbolDemand(window time.Duration, threshold int, opts ...Option) SymbolDemand {
+	o := newOptions(opts)
+
+	d := &symbolDemand{
+		window:    window,
+		threshold: float64(threshold),
+		now:       o.now,
+		current:   make(map[string]int),
+		previous:  make(map[string]int),
+	}
+	d.windowStart = d.now()
+
+	return d
+}
+
+func (d *symbolDemand) Reference(symbols ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rotate()
+	for _, symbol := range symbols {
+		d.current[symbol]++
+	}
+}
+
+func (d *symbolDemand) Hot(symbol string) bool {
+	if d.threshold <= 0 || d.window <= 0 {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rotate()
+
+	// La ventana anterior pesa según cuánto se superpone con la última ventana
+	elapsed := float64(d.now().Sub(d.windowStart)) / float64(d.window)
+	count := float64(d.current[symbol]) + float64(d.previous[symbol])*(1-elapsed)
+
+	return count >= d.threshold
+}
+
+// rotate avanza la ventana si corresponde. Requiere d.mu.
+func (d *symbolDemand) rotate() {
+	elapsed := d.now().Sub(d.windowStart)
+	if elapsed < d.window {
+		return
+	}
+
+	if elapsed < 2*d.window {
+		d.previous = d.current
+	} else {
+		d.previous = make(map[string]int)
+	}
+	d.current = make(map[string]int)
+	d.windowStart = d.windowStart.Add(elapsed.Truncate(d.window))
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolDemand(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	d := NewSymbolDemand(time.Minute, 4).(*symbolDemand)
+	d.now = func() time.Time { return now }
+	d.windowStart = now
+
+	d.Reference("BTCUSD", "ETHUSD")
+	d.Reference("BTCUSD")
+	d.Reference("BTCUSD")
+	assert.False(t, d.Hot("BTCUSD"))
+
+	d.Reference("BTCUSD")
+	assert.True(t, d.Hot("BTCUSD"))
+	assert.False(t, d.Hot("ETHUSD"))
+
+	// En la ventana siguiente las referencias anteriores pierden peso
+	now = now.Add(75 * time.Second)
+	assert.False(t, d.Hot("BTCUSD"))
+	d.Reference("BTCUSD")
+	assert.True(t, d.Hot("BTCUSD"))
+
+	// Sin referencias por más de dos ventanas el símbolo se enfría
+	now = now.Add(3 * time.Minute)
+	assert.False(t, d.Hot("BTCUSD"))
+}
+
+func TestSymbolDemandDisabled(t *testing.T) {
+	d := NewSymbolDemand(time.Minute, 0)
+	d.Reference("BTCUSD")
+	assert.False(t, d.Hot("BTCUSD"))
+}
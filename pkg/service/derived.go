@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// maxDerivedDepth límite de anidamiento de derivados sobre derivados, para
+// cortar definiciones cíclicas
+const maxDerivedDepth = 8
+
+// ParseDerivedInstrument interpreta definiciones con el formato 'SIMBOLO;tipo;leg1[;leg2]'
+func ParseDerivedInstrument(definition string) (instrument model.DerivedInstrument, err error) {
+	fields := strings.Split(definition, ";")
+	if len(fields) < 3 || fields[0] == "" {
+		return instrument, fmt.Errorf("invalid derived instrument %q: expected 'SYMBOL;kind;leg1[;leg2]'", definition)
+	}
+
+	instrument = model.DerivedInstrument{
+		Symbol: fields[0],
+		Kind:   fields[1],
+		Legs:   fields[2:],
+	}
+
+	legs := len(instrument.Legs)
+	switch {
+	case instrument.Kind == model.DerivedCross && legs != 2,
+		instrument.Kind == model.DerivedInverse && legs != 1,
+		instrument.Kind == model.DerivedProduct && legs < 2:
+		return instrument, fmt.Errorf("invalid derived instrument %q: wrong number of legs for %s", definition, instrument.Kind)
+	case instrument.Kind != model.DerivedCross &&
+		instrument.Kind != model.DerivedInverse &&
+		instrument.Kind != model.DerivedProduct:
+		return instrument, fmt.Errorf("invalid derived instrument %q: unknown kind %q", definition, instrument.Kind)
+	}
+
+	for _, leg := range instrument.Legs {
+		if leg == "" || leg == instrument.Symbol {
+			return instrument, fmt.Errorf("invalid derived instrument %q: invalid leg %q", definition, leg)
+		}
+	}
+
+	return instrument, nil
+}
+
+// updateDerived recalcula y almacena los instrumentos que usan el símbolo como leg
+func (s *marketDataService) updateDerived(symbol string, depth int) {
+	if depth >= maxDerivedDepth {
+		s.logger.Error("max derived instrument depth reached", zap.String("symbol", symbol))
+		return
+	}
+
+	for _, instrument := range s.derivedByLeg[symbol] {
+		md, err := s.computeDerived(instrument)
+		if err != nil {
+			s.logger.Debug("derived instrument not updated",
+				zap.String("symbol", instrument.Symbol),
+				zap.Error(err))
+			continue
+		}
+
+		if err := s.mdStore.SetOrUpdateMD(md); err != nil {
+			s.logger.Error("error updating MD", zap.Any("md", md), zap.Error(err))
+			continue
+		}
+
+		s.notify(md)
+		s.updateDerived(instrument.Symbol, depth+1)
+	}
+}
+
+// computeDerived calcula el precio de un instrumento derivado. La fecha del
+// precio es la del leg más antiguo.
+func (s *marketDataService) computeDerived(instrument model.DerivedInstrument) (md model.MarketData, err error) {
+	legs := make([]model.MarketData, 0, len(instrument.Legs))
+	sources := map[string]bool{}
+
+	for _, symbol := range instrument.Legs {
+		leg, err := s.mdStore.GetMD(symbol)
+		if err != nil {
+			return md, fmt.Errorf("leg %s: %w", symbol, err)
+		}
+
+		if len(legs) == 0 || leg.LastPriceDateTime.Before(md.LastPriceDateTime) {
+			md.LastPriceDateTime = leg.LastPriceDateTime
+		}
+		for _, source := range leg.Sources {
+			sources[source] = true
+		}
+
+		legs = append(legs, leg)
+	}
+
+	var price decimal.Decimal
+
+	switch instrument.Kind {
+	case model.DerivedCross:
+		if legs[1].LastPrice.IsZero() {
+			return md, fmt.Errorf("leg %s has zero price", legs[1].Symbol)
+		}
+		price = legs[0].LastPrice.Div(legs[1].LastPrice)
+	case model.DerivedInverse:
+		if legs[0].LastPrice.IsZero() {
+			return md, fmt.Errorf("leg %s has zero price", legs[0].Symbol)
+		}
+		price = decimal.NewFromInt(1).Div(legs[0].LastPrice)
+	case model.DerivedProduct:
+		price = decimal.NewFromInt(1)
+		for _, leg := range legs {
+			price = price.Mul(leg.LastPrice)
+		}
+	default:
+		return md, fmt.Errorf("unknown derived instrument kind %q", instrument.Kind)
+	}
+
+	md.Symbol = instrument.Symbol
+	md.LastPrice = price
+	for source := range sources {
+		md.Sources = append(md.Sources, source)
+	}
+	sort.Strings(md.Sources)
+
+	return md, nil
+}
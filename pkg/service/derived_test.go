@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store/memory"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestDerivedInstruments(t *testing.T) {
+	instruments := []model.DerivedInstrument{}
+	for _, definition := range []string{
+		"ETHBTC;cross;ETHUSD;BTCUSD",
+		"USDBTC;inverse;BTCUSD",
+		"BTCARS;product;BTCUSD;USDARS",
+		"BTCETH;inverse;ETHBTC",
+	} {
+		instrument, err := ParseDerivedInstrument(definition)
+		assert.NoError(t, err)
+		instruments = append(instruments, instrument)
+	}
+
+	mdStore := memory.NewMarketDataStore()
+	mdService := NewMarketDataService(zap.NewNop(), mdStore, WithDerivedInstruments(instruments))
+
+	ts1, _ := time.Parse(time.RFC3339, "2021-09-23T12:34:56Z")
+	ts2, _ := time.Parse(time.RFC3339, "2021-09-23T13:34:56Z")
+
+	s := mdService.(*marketDataService)
+	s.processMD(model.MarketData{
+		Symbol:            "BTCUSD",
+		LastPrice:         decimal.RequireFromString("40000"),
+		LastPriceDateTime: ts2,
+		Sources:           []string{"cryptonator"},
+	})
+
+	// ETHBTC todavía no tiene todos sus legs
+	_, err := mdStore.GetMD("ETHBTC")
+	assert.Error(t, err)
+
+	md, err := mdStore.GetMD("USDBTC")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.000025", md.LastPrice.String())
+
+	s.processMD(model.MarketData{
+		Symbol:            "ETHUSD",
+		LastPrice:         decimal.RequireFromString("3000"),
+		LastPriceDateTime: ts1,
+		Sources:           []string{"coingecko"},
+	})
+
+	md, err = mdStore.GetMD("ETHBTC")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.075", md.LastPrice.String())
+	assert.Equal(t, ts1, md.LastPriceDateTime)
+	assert.Equal(t, []string{"coingecko", "cryptonator"}, md.Sources)
+
+	// Derivado de un derivado
+	md, err = mdStore.GetMD("BTCETH")
+	assert.NoError(t, err)
+	assert.Equal(t, "13.3333333333333333", md.LastPrice.String())
+}
+
+func TestParseDerivedInstrumentErrors(t *testing.T) {
+	for _, definition := range []string{
+		"ETHBTC;cross;ETHUSD",
+		"USDBTC;inverse;BTCUSD;ETHUSD",
+		"X;unknown;A;B",
+		"X;product;X;A",
+		"ETHBTC",
+	} {
+		_, err := ParseDerivedInstrument(definition)
+		assert.Error(t, err, definition)
+	}
+}
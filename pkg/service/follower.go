@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"go.uber.org/zap"
+)
+
+// FollowerService mientras la réplica no es líder, lee periódicamente los
+// precios que el líder persiste en el snapshot y publica los nuevos en el
+// channel de market data
+type FollowerService interface {
+	lifecycle.Component
+}
+
+type followerService struct {
+	logger        *zap.Logger
+	snapshotStore store.MarketDataSnapshotStore
+	mdChannel     model.MdChannel
+	interval      time.Duration
+	isLeader      func() bool
+	routines      lifecycle.Group
+
+	// published fecha del último precio publicado por símbolo
+	published map[string]time.Time
+}
+
+func NewFollowerService(
+	logger *zap.Logger,
+	snapshotStore store.MarketDataSnapshotStore,
+	mdChannel model.MdChannel,
+	interval time.Duration,
+	isLeader func() bool,
+) FollowerService {
+	return &followerService{
+		logger:        logger,
+		snapshotStore: snapshotStore,
+		mdChannel:     mdChannel,
+		interval:      interval,
+		isLeader:      isLeader,
+		published:     make(map[string]time.Time),
+	}
+}
+
+func (s *followerService) Start(ctx context.Context) error {
+	s.routines.Go(func(ctx context.Context) {
+		for {
+			if !s.isLeader() {
+				s.sync(ctx)
+			}
+
+			if !lifecycle.Sleep(ctx, s.interval) {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (s *followerService) Stop(ctx context.Context) error {
+	return s.routines.Stop(ctx)
+}
+
+// sync publica los precios del snapshot posteriores a los ya publicados
+func (s *followerService) sync(ctx context.Context) {
+	mds, err := s.snapshotStore.LoadMD()
+	if err != nil {
+		s.logger.Error("error loading leader market data", zap.Error(err))
+		return
+	}
+
+	for _, md := range mds {
+		if !md.LastPriceDateTime.After(s.published[md.Symbol]) {
+			continue
+		}
+
+		select {
+		case s.mdChannel <- md:
+			s.published[md.Symbol] = md.LastPriceDateTime
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestFollowerPublishesNewLeaderPrices(t *testing.T) {
+	ts := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	btc := model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("45000"), LastPriceDateTime: ts}
+	eth := model.MarketData{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("3000"), LastPriceDateTime: ts}
+	btc2 := model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("45100"), LastPriceDateTime: ts.Add(time.Second)}
+
+	snapshotStoreMock := new(mocks.MarketDataSnapshotStore)
+	snapshotStoreMock.On("LoadMD").Return([]model.MarketData{btc, eth}, nil).Once()
+	snapshotStoreMock.On("LoadMD").Return([]model.MarketData{btc2, eth}, nil)
+
+	var leader int32
+	mdChannel := make(model.MdChannel)
+	s := NewFollowerService(zap.NewNop(), snapshotStoreMock, mdChannel, time.Millisecond,
+		func() bool { return atomic.LoadInt32(&leader) == 1 })
+	assert.NoError(t, s.Start(context.Background()))
+
+	// Sólo se publican los precios posteriores a los ya publicados
+	assert.Equal(t, btc, <-mdChannel)
+	assert.Equal(t, eth, <-mdChannel)
+	assert.Equal(t, btc2, <-mdChannel)
+
+	// Siendo líder no se lee el snapshot
+	atomic.StoreInt32(&leader, 1)
+	assert.NoError(t, s.Stop(context.Background()))
+	calls := len(snapshotStoreMock.Calls)
+	assert.NoError(t, s.Start(context.Background()))
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, s.Stop(context.Background()))
+	assert.Len(t, snapshotStoreMock.Calls, calls)
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/notifier"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// FreshnessService sigue por símbolo la última actualización, los errores
+// consecutivos del proveedor y los períodos sin actualizaciones (gaps).
+// Implementa crypto.Reporter para recibir el resultado de las consultas.
+type FreshnessService interface {
+	MarketDataObserver
+	Success(symbol string)
+	Failure(symbol string, err error)
+	// Track agrega símbolos a seguir aunque todavía no hayan recibido precio
+	Track(symbols ...string)
+	// SetStaleThreshold define un umbral de desactualización propio para un símbolo
+	SetStaleThreshold(symbol string, threshold time.Duration)
+	// Status devuelve el estado de todos los símbolos seguidos, ordenados por símbolo
+	Status() model.GetMarketDataStatusResponse
+	lifecycle.Component
+}
+
+type freshnessService struct {
+	logger   *zap.Logger
+	notifier notifier.Notifier
+	// staleThreshold antigüedad a partir de la cual un símbolo se considera desactualizado
+	staleThreshold time.Duration
+	checkInterval  time.Duration
+	notifyTimeout  time.Duration
+	metrics        *freshnessMetrics
+	now            func() time.Time
+	started        time.Time
+	routines       lifecycle.Group
+
+	mu      sync.Mutex
+	symbols map[string]*symbolFreshness
+}
+
+type symbolFreshness struct {
+	// staleThreshold umbral propio del símbolo; con 0 se usa el general
+	staleThreshold      time.Duration
+	lastUpdate          time.Time
+	consecutiveFailures int
+	lastError           string
+	lastErrorDateTime   time.Time
+	gaps                int
+	lastGap             time.Duration
+	maxGap              time.Duration
+	// alerted indica que se notificó el símbolo como desactualizado
+	alerted bool
+}
+
+type freshnessMetrics struct {
+	lastUpdate          *prometheus.GaugeVec
+	age                 *prometheus.GaugeVec
+	stale               *prometheus.GaugeVec
+	consecutiveFailures *prometheus.GaugeVec
+	updates             *prometheus.CounterVec
+	failures            *prometheus.CounterVec
+	gaps                *prometheus.HistogramVec
+}
+
+func newFreshnessMetrics(registerer prometheus.Registerer) *freshnessMetrics {
+	m := &freshnessMetrics{
+		lastUpdate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "mtz_crypto_marketdata_last_update_timestamp_seconds",
+			Help: "Unix timestamp of the last market data update per symbol",
+		}, []string{"symbol"}),
+		age: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "mtz_crypto_marketdata_age_seconds",
+			Help: "Seconds since the last market data update per symbol",
+		}, []string{"symbol"}),
+		stale: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "mtz_crypto_marketdata_stale",
+			Help: "1 if the symbol exceeded the stale threshold, 0 otherwise",
+		}, []string{"symbol"}),
+		consecutiveFailures: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "mtz_crypto_marketdata_consecutive_failures",
+			Help: "Consecutive provider failures per symbol",
+		}, []string{"symbol"}),
+		updates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mtz_crypto_marketdata_updates_total",
+			Help: "Market data updates per symbol",
+		}, []string{"symbol"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mtz_crypto_marketdata_failures_total",
+			Help: "Provider failures per symbol",
+		}, []string{"symbol"}),
+		gaps: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mtz_crypto_marketdata_gap_duration_seconds",
+			Help:    "Duration of the periods without updates longer than the stale threshold",
+			Buckets: prometheus.ExponentialBuckets(30, 2, 10),
+		}, []string{"symbol"}),
+	}
+
+	registerer.MustRegister(m.lastUpdate, m.age, m.stale, m.consecutiveFailures, m.updates, m.failures, m.gaps)
+
+	return m
+}
+
+func NewFreshnessService(
+	logger *zap.Logger,
+	registerer prometheus.Registerer,
+	notifier notifier.Notifier,
+	staleThreshold time.Duration,
+	checkInterval time.Duration,
+	notifyTimeout time.Duration,
+	opts ...Option,
+) FreshnessService {
+	o := newOptions(opts)
+
+	s := &freshnessService{
+		logger:         logger,
+		notifier:       notifier,
+		staleThreshold: staleThreshold,
+		checkInterval:  checkInterval,
+		notifyTimeout:  notifyTimeout,
+		metrics:        newFreshnessMetrics(registerer),
+		now:            o.now,
+		symbols:        make(map[string]*symbolFreshness),
+	}
+	s.started = s.now()
+
+	return s
+}
+
+func (s *freshnessService) Track(symbols ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, symbol := range symbols {
+		s.get(symbol)
+	}
+}
+
+func (s *freshnessService) SetStaleThreshold(symbol string, threshold time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.get(symbol).staleThreshold = threshold
+}
+
+// threshold umbral de desactualización del símbolo
+func (s *freshnessService) threshold(f *symbolFreshness) time.Duration {
+	if f.staleThreshold > 0 {
+		return f.staleThreshold
+	}
+
+	return s.staleThreshold
+}
+
+// get devuelve el estado del símbolo, creándolo si no existe. Requiere s.mu.
+func (s *freshnessService) get(symbol string) *symbolFreshness {
+	f, found := s.symbols[symbol]
+	if !found {
+		f = &symbolFreshness{}
+		s.symbols[symbol] = f
+	}
+
+	return f
+}
+
+func (s *freshnessService) OnMD(md model.MarketData) {
+	if md.Symbol == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	f := s.get(md.Symbol)
+
+	since := f.lastUpdate
+	if since.IsZero() {
+		since = s.started
+	}
+	if gap := now.Sub(since); gap > s.threshold(f) {
+		f.gaps++
+		f.lastGap = gap
+		if gap > f.maxGap {
+			f.maxGap = gap
+		}
+		s.metrics.gaps.WithLabelValues(md.Symbol).Observe(gap.Seconds())
+	}
+
+	f.lastUpdate = now
+	s.metrics.lastUpdate.WithLabelValues(md.Symbol).Set(float64(now.UnixNano()) / 1e9)
+	s.metrics.age.WithLabelValues(md.Symbol).Set(0)
+	s.metrics.stale.WithLabelValues(md.Symbol).Set(0)
+	s.metrics.updates.WithLabelValues(md.Symbol).Inc()
+
+	if f.alerted {
+		f.alerted = false
+		s.notify(model.FreshnessEventRecovered, md.Symbol, f, now)
+	}
+}
+
+func (s *freshnessService) Success(symbol string) {
+	if symbol == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.get(symbol).consecutiveFailures = 0
+	s.metrics.consecutiveFailures.WithLabelValues(symbol).Set(0)
+}
+
+// Failure registra un error del proveedor. Los errores que no corresponden a
+// un símbolo se reflejan en la antigüedad de los precios.
+func (s *freshnessService) Failure(symbol string, err error) {
+	if symbol == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := s.get(symbol)
+	f.consecutiveFailures++
+	f.lastErrorDateTime = s.now()
+	if err != nil {
+		f.lastError = err.Error()
+	}
+
+	s.metrics.consecutiveFailures.WithLabelValues(symbol).Set(float64(f.consecutiveFailures))
+	s.metrics.failures.WithLabelValues(symbol).Inc()
+}
+
+func (s *freshnessService) Status() model.GetMarketDataStatusResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	resp := model.GetMarketDataStatusResponse{
+		StaleThresholdSeconds: s.staleThreshold.Seconds(),
+		Symbols:               make([]model.SymbolStatus, 0, len(s.symbols)),
+	}
+
+	for symbol, f := range s.symbols {
+		resp.Symbols = append(resp.Symbols, s.symbolStatus(symbol, f, now))
+	}
+
+	sort.Slice(resp.Symbols, func(i, j int) bool {
+		return resp.Symbols[i].Symbol < resp.Symbols[j].Symbol
+	})
+
+	return resp
+}
+
+// symbolStatus arma el estado de un símbolo. Requiere s.mu.
+func (s *freshnessService) symbolStatus(symbol string, f *symbolFreshness, now time.Time) model.SymbolStatus {
+	status := model.SymbolStatus{
+		Symbol:              symbol,
+		Status:              model.SymbolStatusFresh,
+		ConsecutiveFailures: f.consecutiveFailures,
+		LastError:           f.lastError,
+		Gaps:                f.gaps,
+		LastGapSeconds:      f.lastGap.Seconds(),
+		MaxGapSeconds:       f.maxGap.Seconds(),
+	}
+
+	if !f.lastErrorDateTime.IsZero() {
+		lastErrorDateTime := f.lastErrorDateTime
+		status.LastErrorDateTime = &lastErrorDateTime
+	}
+
+	if f.lastUpdate.IsZero() {
+		status.Status = model.SymbolStatusUnknown
+		if now.Sub(s.started) > s.threshold(f) {
+			status.Status = model.SymbolStatusStale
+		}
+		return status
+	}
+
+	lastUpdate := f.lastUpdate
+	status.LastUpdate = &lastUpdate
+	status.AgeSeconds = now.Sub(f.lastUpdate).Seconds()
+	if now.Sub(f.lastUpdate) > s.threshold(f) {
+		status.Status = model.SymbolStatusStale
+	}
+
+	return status
+}
+
+func (s *freshnessService) Start(ctx context.Context) error {
+	s.routines.Go(func(ctx context.Context) {
+		ticker := time.NewTicker(s.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.check()
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	s.logger.Info("freshness monitor started",
+		zap.Duration("staleThreshold", s.staleThreshold),
+		zap.Duration("checkInterval", s.checkInterval))
+
+	return nil
+}
+
+func (s *freshnessService) Stop(ctx context.Context) error {
+	return s.routines.Stop(ctx)
+}
+
+// check actualiza la antigüedad de cada símbolo y notifica los que superaron el umbral
+func (s *freshnessService) check() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for symbol, f := range s.symbols {
+		status := s.symbolStatus(symbol, f, now)
+
+		stale := 0.0
+		if status.Status == model.SymbolStatusStale {
+			stale = 1
+		}
+		s.metrics.stale.WithLabelValues(symbol).Set(stale)
+		if !f.lastUpdate.IsZero() {
+			s.metrics.age.WithLabelValues(symbol).Set(status.AgeSeconds)
+		}
+
+		if status.Status == model.SymbolStatusStale && !f.alerted {
+			f.alerted = true
+			s.logger.Warn("market data is stale",
+				zap.String("symbol", symbol),
+				zap.Float64("ageSeconds", status.AgeSeconds),
+				zap.Int("consecutiveFailures", status.ConsecutiveFailures))
+			s.notify(model.FreshnessEventStale, symbol, f, now)
+		}
+	}
+}
+
+// notify envía el evento sin bloquear al llamador. Requiere s.mu.
+func (s *freshnessService) notify(event string, symbol string, f *symbolFreshness, now time.Time) {
+	payload := model.FreshnessEvent{
+		Event:    event,
+		DateTime: now,
+		Status:   s.symbolStatus(symbol, f, now),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.notifyTimeout)
+		defer cancel()
+
+		if err := s.notifier.Notify(ctx, payload); err != nil {
+			s.logger.Error("error sending freshness notification",
+				zap.String("symbol", symbol),
+				zap.String("event", event),
+				zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type channelNotifier chan interface{}
+
+func (n channelNotifier) Notify(ctx context.Context, payload interface{}) error {
+	n <- payload
+	return nil
+}
+
+func newTestFreshnessService(events channelNotifier, now *time.Time) *freshnessService {
+	s := NewFreshnessService(zap.NewNop(), prometheus.NewRegistry(), events,
+		time.Minute, time.Second, time.Second).(*freshnessService)
+	s.now = func() time.Time { return *now }
+	s.started = *now
+	s.Track("BTCUSD", "ETHUSD")
+
+	return s
+}
+
+func TestFreshnessStaleAndRecovered(t *testing.T) {
+	now := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	events := make(channelNotifier, 10)
+	s := newTestFreshnessService(events, &now)
+
+	s.OnMD(model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.NewFromInt(45000), LastPriceDateTime: now})
+
+	status := s.Status()
+	assert.Equal(t, 60.0, status.StaleThresholdSeconds)
+	assert.Len(t, status.Symbols, 2)
+	assert.Equal(t, model.SymbolStatusFresh, status.Symbols[0].Status)
+	assert.Equal(t, model.SymbolStatusUnknown, status.Symbols[1].Status)
+
+	// Pasado el umbral ambos símbolos están desactualizados y se notifican una sola vez
+	now = now.Add(2 * time.Minute)
+	s.Failure("BTCUSD", errors.New("timeout"))
+	s.Failure("BTCUSD", errors.New("timeout"))
+	s.check()
+	s.check()
+
+	notified := map[string]model.FreshnessEvent{}
+	for i := 0; i < 2; i++ {
+		event := (<-events).(model.FreshnessEvent)
+		notified[event.Status.Symbol] = event
+	}
+	assert.Equal(t, model.FreshnessEventStale, notified["BTCUSD"].Event)
+	assert.Equal(t, 2, notified["BTCUSD"].Status.ConsecutiveFailures)
+	assert.Equal(t, "timeout", notified["BTCUSD"].Status.LastError)
+	assert.Equal(t, 120.0, notified["BTCUSD"].Status.AgeSeconds)
+	assert.Equal(t, model.SymbolStatusStale, notified["ETHUSD"].Status.Status)
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.stale.WithLabelValues("BTCUSD")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.consecutiveFailures.WithLabelValues("BTCUSD")))
+
+	// Al volver a recibir precio se registra el gap y se notifica la recuperación
+	now = now.Add(time.Minute)
+	s.Success("BTCUSD")
+	s.OnMD(model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.NewFromInt(45100), LastPriceDateTime: now})
+
+	event := (<-events).(model.FreshnessEvent)
+	assert.Equal(t, model.FreshnessEventRecovered, event.Event)
+	assert.Equal(t, "BTCUSD", event.Status.Symbol)
+	assert.Equal(t, 1, event.Status.Gaps)
+	assert.Equal(t, 180.0, event.Status.LastGapSeconds)
+	assert.Equal(t, 0, event.Status.ConsecutiveFailures)
+	assert.Equal(t, 0.0, testutil.ToFloat64(s.metrics.stale.WithLabelValues("BTCUSD")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.updates.WithLabelValues("BTCUSD")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.failures.WithLabelValues("BTCUSD")))
+
+	select {
+	case extra := <-events:
+		t.Fatalf("unexpected notification: %+v", extra)
+	default:
+	}
+}
+
+func TestFreshnessIgnoresGenericFailures(t *testing.T) {
+	now := time.Now()
+	s := newTestFreshnessService(make(channelNotifier, 10), &now)
+
+	s.Failure("", errors.New("connection refused"))
+
+	assert.Len(t, s.Status().Symbols, 2)
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/health"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+)
+
+// NewMarketDataCheck verifica que todos los símbolos configurados tengan un
+// precio con antigüedad menor a maxAge. Los símbolos se obtienen en cada
+// verificación, ya que las suscripciones pueden cambiar en runtime.
+func NewMarketDataCheck(mdStore store.MarketDataStore, symbols func() []string, maxAge time.Duration, opts ...Option) health.Check {
+	o := newOptions(opts)
+
+	return func(ctx context.Context) error {
+		now := o.now()
+
+		for _, symbol := range symbols() {
+			md, err := mdStore.GetMD(symbol)
+			if err != nil {
+				return fmt.Errorf("no price for %s", symbol)
+			}
+
+			if age := now.Sub(md.LastPriceDateTime); maxAge > 0 && age > maxAge {
+				return fmt.Errorf("stale price for %s (age %s)", symbol, age.Truncate(time.Second))
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store/memory"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarketDataCheck(t *testing.T) {
+	mdStore := memory.NewMarketDataStore()
+	check := NewMarketDataCheck(mdStore, func() []string { return []string{"BTCUSD", "ETHUSD"} }, time.Minute)
+
+	_ = mdStore.SetOrUpdateMD(model.MarketData{
+		Symbol:            "BTCUSD",
+		LastPrice:         decimal.NewFromInt(45000),
+		LastPriceDateTime: time.Now(),
+	})
+	assert.EqualError(t, check(context.Background()), "no price for ETHUSD")
+
+	_ = mdStore.SetOrUpdateMD(model.MarketData{
+		Symbol:            "ETHUSD",
+		LastPrice:         decimal.NewFromInt(3000),
+		LastPriceDateTime: time.Now().Add(-2 * time.Minute),
+	})
+	assert.Error(t, check(context.Background()))
+
+	_ = mdStore.SetOrUpdateMD(model.MarketData{
+		Symbol:            "ETHUSD",
+		LastPrice:         decimal.NewFromInt(3000),
+		LastPriceDateTime: time.Now(),
+	})
+	assert.NoError(t, check(context.Background()))
+}
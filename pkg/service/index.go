@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/config"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// IndexSource fuente con la que se publican los niveles de los índices
+const IndexSource = "index"
+
+// indexLevelDecimals decimales del nivel publicado
+const indexLevelDecimals = 8
+
+type IndexService interface {
+	MarketDataObserver
+	GetIndex(symbol string) (rs model.GetIndexResponse, err error)
+	GetIndexHistory(symbol string, limit int) (rs model.GetIndexHistoryResponse, err error)
+	lifecycle.Component
+}
+
+type indexService struct {
+	logger      *zap.Logger
+	mdChannel   model.MdChannel
+	historySize int
+	ticks       chan model.MarketData
+	routines    lifecycle.Group
+
+	mu            sync.RWMutex
+	indices       map[string]*indexState
+	byConstituent map[string][]*indexState
+}
+
+// indexState estado de cálculo de un índice
+type indexState struct {
+	index model.Index
+	// active composición vigente, -1 si todavía no se pudo activar ninguna
+	active int
+	// units unidades de cada componente de la composición vigente
+	units   map[string]decimal.Decimal
+	prices  map[string]model.MarketData
+	last    time.Time
+	level   *model.IndexLevel
+	history []model.IndexLevel
+}
+
+// indexFile formato del archivo de definición de índices
+type indexFile struct {
+	Indices []struct {
+		Symbol       string `mapstructure:"symbol"`
+		Base         string `mapstructure:"base"`
+		Divisor      string `mapstructure:"divisor"`
+		Compositions []struct {
+			Effective    string `mapstructure:"effective"`
+			Divisor      string `mapstructure:"divisor"`
+			Constituents []struct {
+				Symbol string `mapstructure:"symbol"`
+				Weight string `mapstructure:"weight"`
+				Units  string `mapstructure:"units"`
+			} `mapstructure:"constituents"`
+		} `mapstructure:"compositions"`
+	} `mapstructure:"indices"`
+}
+
+// LoadIndices lee las definiciones de índices desde un archivo YAML o JSON
+func LoadIndices(path string) (indices []model.Index, err error) {
+	var file indexFile
+	if err := config.ReadFile(path, &file); err != nil {
+		return nil, err
+	}
+
+	for _, def := range file.Indices {
+		index := model.Index{Symbol: def.Symbol, Base: decimal.NewFromInt(100)}
+		if index.Symbol == "" {
+			return nil, fmt.Errorf("index symbol is required")
+		}
+
+		if def.Base != "" {
+			if index.Base, err = decimal.NewFromString(def.Base); err != nil {
+				return nil, fmt.Errorf("index %s: invalid base: %w", def.Symbol, err)
+			}
+		}
+
+		if len(def.Compositions) == 0 {
+			return nil, fmt.Errorf("index %s: at least one composition is required", def.Symbol)
+		}
+
+		for _, c := range def.Compositions {
+			composition := model.IndexComposition{Divisor: decimal.NewFromInt(1)}
+
+			if c.Effective != "" {
+				if composition.Effective, err = time.Parse(time.RFC3339, c.Effective); err != nil {
+					return nil, fmt.Errorf("index %s: invalid effective date: %w", def.Symbol, err)
+				}
+			}
+
+			divisor := c.Divisor
+			if divisor == "" {
+				divisor = def.Divisor
+			}
+			if divisor != "" {
+				if composition.Divisor, err = decimal.NewFromString(divisor); err != nil {
+					return nil, fmt.Errorf("index %s: invalid divisor: %w", def.Symbol, err)
+				}
+			}
+			if composition.Divisor.IsZero() {
+				return nil, fmt.Errorf("index %s: divisor must not be zero", def.Symbol)
+			}
+
+			for _, k := range c.Constituents {
+				constituent := model.IndexConstituent{Symbol: k.Symbol}
+
+				switch {
+				case k.Symbol == "" || k.Symbol == def.Symbol:
+					return nil, fmt.Errorf("index %s: invalid constituent %q", def.Symbol, k.Symbol)
+				case k.Units != "":
+					units, err := decimal.NewFromString(k.Units)
+					if err != nil {
+						return nil, fmt.Errorf("index %s: invalid units for %s: %w", def.Symbol, k.Symbol, err)
+					}
+					constituent.Units = decimal.NullDecimal{Decimal: units, Valid: true}
+				case k.Weight != "":
+					weight, err := decimal.NewFromString(k.Weight)
+					if err != nil {
+						return nil, fmt.Errorf("index %s: invalid weight for %s: %w", def.Symbol, k.Symbol, err)
+					}
+					constituent.Weight = decimal.NullDecimal{Decimal: weight, Valid: true}
+				default:
+					return nil, fmt.Errorf("index %s: weight or units required for %s", def.Symbol, k.Symbol)
+				}
+
+				composition.Constituents = append(composition.Constituents, constituent)
+			}
+
+			index.Compositions = append(index.Compositions, composition)
+		}
+
+		sort.Slice(index.Compositions, func(i, j int) bool {
+			return index.Compositions[i].Effective.Before(index.Compositions[j].Effective)
+		})
+
+		indices = append(indices, index)
+	}
+
+	return indices, nil
+}
+
+func NewIndexService(
+	logger *zap.Logger,
+	indices []model.Index,
+	mdChannel model.MdChannel,
+	historySize int,
+	queueSize int,
+) IndexService {
+	s := &indexService{
+		logger:        logger,
+		mdChannel:     mdChannel,
+		historySize:   historySize,
+		ticks:         make(chan model.MarketData, queueSize),
+		indices:       make(map[string]*indexState),
+		byConstituent: make(map[string][]*indexState),
+	}
+
+	for _, index := range indices {
+		state := &indexState{index: index, active: -1, prices: map[string]model.MarketData{}}
+		s.indices[index.Symbol] = state
+
+		symbols := map[string]bool{}
+		for _, c := range index.Compositions {
+			for _, k := range c.Constituents {
+				symbols[k.Symbol] = true
+			}
+		}
+		for symbol := range symbols {
+			s.byConstituent[symbol] = append(s.byConstituent[symbol], state)
+		}
+	}
+
+	return s
+}
+
+// OnMD encola los ticks de componentes de índices. Se ejecuta en el consumidor
+// de market data, por lo que nunca bloquea: si la cola está llena se descarta.
+func (s *indexService) OnMD(md model.MarketData) {
+	if _, found := s.byConstituent[md.Symbol]; !found {
+		return
+	}
+
+	select {
+	case s.ticks <- md:
+	default:
+		s.logger.Warn("index queue full, tick discarded", zap.String("symbol", md.Symbol))
+	}
+}
+
+func (s *indexService) Start(ctx context.Context) error {
+	s.routines.Go(func(ctx context.Context) {
+		for {
+			var md model.MarketData
+			select {
+			case md = <-s.ticks:
+			case <-ctx.Done():
+				return
+			}
+
+			for _, level := range s.update(md) {
+				select {
+				case s.mdChannel <- level:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	})
+
+	s.logger.Info("indexService started", zap.Int("indices", len(s.indices)))
+
+	return nil
+}
+
+// Stop deja de publicar niveles. Los ticks recibidos luego se descartan.
+func (s *indexService) Stop(ctx context.Context) error {
+	return s.routines.Stop(ctx)
+}
+
+// update recalcula los índices que contienen el símbolo y devuelve los nuevos niveles
+func (s *indexService) update(md model.MarketData) (levels []model.MarketData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, state := range s.byConstituent[md.Symbol] {
+		state.prices[md.Symbol] = md
+		if md.LastPriceDateTime.After(state.last) {
+			state.last = md.LastPriceDateTime
+		}
+
+		s.rebalance(state)
+
+		level, ok := s.computeLevel(state)
+		if !ok {
+			continue
+		}
+
+		state.level = &model.IndexLevel{Level: level.LastPrice, DateTime: level.LastPriceDateTime}
+		state.history = append(state.history, *state.level)
+		if len(state.history) > s.historySize {
+			state.history = state.history[len(state.history)-s.historySize:]
+		}
+
+		levels = append(levels, level)
+	}
+
+	return levels
+}
+
+// rebalance activa la composición vigente a la fecha del último tick. Las
+// ponderaciones se convierten en unidades con los precios del momento y el nivel
+// que resulta de la composición anterior con esos precios (incluido el tick que
+// dispara el rebalanceo), de modo que el nivel del índice no salte.
+func (s *indexService) rebalance(state *indexState) {
+	target := -1
+	for i, c := range state.index.Compositions {
+		if !c.Effective.After(state.last) {
+			target = i
+		}
+	}
+
+	if target < 0 || target == state.active {
+		return
+	}
+
+	composition := state.index.Compositions[target]
+	reference := state.index.Base
+	if level, ok := s.computeLevel(state); ok {
+		reference = level.LastPrice
+	} else if state.level != nil {
+		reference = state.level.Level
+	}
+
+	units := make(map[string]decimal.Decimal, len(composition.Constituents))
+	for _, k := range composition.Constituents {
+		if k.Units.Valid {
+			units[k.Symbol] = k.Units.Decimal
+			continue
+		}
+
+		price, found := state.prices[k.Symbol]
+		if !found || price.LastPrice.IsZero() {
+			// Se mantiene la composición anterior hasta tener todos los precios
+			return
+		}
+		units[k.Symbol] = k.Weight.Decimal.Mul(reference).Mul(composition.Divisor).Div(price.LastPrice)
+	}
+
+	s.logger.Info("index rebalanced",
+		zap.String("index", state.index.Symbol),
+		zap.Time("effective", composition.Effective))
+
+	state.active = target
+	state.units = units
+}
+
+// computeLevel calcula el nivel del índice: suma de unidades por precio sobre
+// el divisor. La fecha es la del componente con el precio más antiguo.
+func (s *indexService) computeLevel(state *indexState) (md model.MarketData, ok bool) {
+	if state.active < 0 {
+		return md, false
+	}
+
+	composition := state.index.Compositions[state.active]
+	total := decimal.Zero
+
+	for i, k := range composition.Constituents {
+		price, found := state.prices[k.Symbol]
+		if !found {
+			return md, false
+		}
+
+		total = total.Add(state.units[k.Symbol].Mul(price.LastPrice))
+		if i == 0 || price.LastPriceDateTime.Before(md.LastPriceDateTime) {
+			md.LastPriceDateTime = price.LastPriceDateTime
+		}
+	}
+
+	md.Symbol = state.index.Symbol
+	md.LastPrice = total.Div(composition.Divisor).Round(indexLevelDecimals)
+	md.Sources = []string{IndexSource}
+
+	return md, true
+}
+
+func (s *indexService) GetIndex(symbol string) (rs model.GetIndexResponse, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, found := s.indices[symbol]
+	if !found {
+		return rs, model.ErrIndexNotFound
+	}
+
+	rs.Symbol = symbol
+	rs.Constituents = []model.IndexConstituent{}
+
+	active := state.active
+	if active < 0 {
+		active = 0
+	}
+	composition := state.index.Compositions[active]
+	rs.Divisor = composition.Divisor
+
+	if state.active >= 0 {
+		effective := composition.Effective
+		rs.Effective = &effective
+	}
+
+	if active+1 < len(state.index.Compositions) {
+		next := state.index.Compositions[active+1].Effective
+		rs.NextRebalance = &next
+	}
+
+	if state.level != nil {
+		rs.Level = decimal.NullDecimal{Decimal: state.level.Level, Valid: true}
+		dateTime := state.level.DateTime
+		rs.DateTime = &dateTime
+	}
+
+	for _, k := range composition.Constituents {
+		if units, found := state.units[k.Symbol]; found && state.active >= 0 {
+			k.Units = decimal.NullDecimal{Decimal: units, Valid: true}
+		}
+		if price, found := state.prices[k.Symbol]; found {
+			k.LastPrice = decimal.NullDecimal{Decimal: price.LastPrice, Valid: true}
+		}
+		rs.Constituents = append(rs.Constituents, k)
+	}
+
+	return rs, nil
+}
+
+func (s *indexService) GetIndexHistory(symbol string, limit int) (rs model.GetIndexHistoryResponse, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, found := s.indices[symbol]
+	if !found {
+		return rs, model.ErrIndexNotFound
+	}
+
+	history := state.history
+	if limit > 0 && len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+
+	rs.Symbol = symbol
+	rs.History = append([]model.IndexLevel{}, history...)
+
+	return rs, nil
+}
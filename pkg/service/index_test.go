@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const testIndexFile = `
+indices:
+  - symbol: TOP2EW
+    base: 100
+    compositions:
+      - effective: 2021-01-01T00:00:00Z
+        constituents:
+          - symbol: BTCUSD
+            weight: 0.5
+          - symbol: ETHUSD
+            weight: 0.5
+      - effective: 2021-07-01T00:00:00Z
+        constituents:
+          - symbol: BTCUSD
+            weight: 0.25
+          - symbol: ETHUSD
+            weight: 0.75
+  - symbol: BASKET
+    divisor: 2
+    compositions:
+      - constituents:
+          - symbol: BTCUSD
+            units: 1
+          - symbol: ETHUSD
+            units: 10
+`
+
+func loadTestIndices(t *testing.T) []model.Index {
+	path := filepath.Join(t.TempDir(), "indices.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(testIndexFile), 0o600))
+
+	indices, err := LoadIndices(path)
+	assert.NoError(t, err)
+
+	return indices
+}
+
+func tick(symbol, price, date string) model.MarketData {
+	ts, _ := time.Parse(time.RFC3339, date)
+	return model.MarketData{Symbol: symbol, LastPrice: decimal.RequireFromString(price), LastPriceDateTime: ts}
+}
+
+func TestIndexLevels(t *testing.T) {
+	indices := loadTestIndices(t)
+	assert.Len(t, indices, 2)
+
+	s := NewIndexService(zap.NewNop(), indices, make(model.MdChannel), 10, 10).(*indexService)
+
+	// Sin todos los precios no hay nivel
+	assert.Empty(t, s.update(tick("BTCUSD", "40000", "2021-03-01T00:00:00Z")))
+
+	levels := s.update(tick("ETHUSD", "2000", "2021-03-01T00:00:01Z"))
+	assert.Len(t, levels, 2)
+	byIndex := map[string]model.MarketData{}
+	for _, level := range levels {
+		byIndex[level.Symbol] = level
+	}
+	assert.Equal(t, "100", byIndex["TOP2EW"].LastPrice.String())
+	assert.Equal(t, []string{IndexSource}, byIndex["TOP2EW"].Sources)
+	assert.Equal(t, "30000", byIndex["BASKET"].LastPrice.String())
+
+	// BTC +10%: el índice equiponderado sube 5%
+	levels = s.update(tick("BTCUSD", "44000", "2021-03-02T00:00:00Z"))
+	assert.Equal(t, "105", levels[0].LastPrice.String())
+
+	// Rebalanceo con ETH +10%: el nivel incluye la suba del tick que lo dispara
+	// y cambian las unidades
+	levels = s.update(tick("ETHUSD", "2200", "2021-07-01T00:00:00Z"))
+	assert.Equal(t, "110", levels[0].LastPrice.String())
+
+	rs, err := s.GetIndex("TOP2EW")
+	assert.NoError(t, err)
+	assert.Equal(t, "110", rs.Level.Decimal.String())
+	assert.Equal(t, "2021-07-01T00:00:00Z", rs.Effective.Format(time.RFC3339))
+	assert.Nil(t, rs.NextRebalance)
+	assert.Len(t, rs.Constituents, 2)
+	assert.Equal(t, "0.75", rs.Constituents[1].Weight.Decimal.String())
+	assert.Equal(t, "0.0375", rs.Constituents[1].Units.Decimal.String())
+
+	// Con las unidades nuevas, ETH +10% mueve el índice 7.5%
+	levels = s.update(tick("ETHUSD", "2420", "2021-07-02T00:00:00Z"))
+	assert.Equal(t, "118.25", levels[0].LastPrice.String())
+
+	history, err := s.GetIndexHistory("TOP2EW", 2)
+	assert.NoError(t, err)
+	assert.Len(t, history.History, 2)
+
+	_, err = s.GetIndex("UNKNOWN")
+	assert.ErrorIs(t, err, model.ErrIndexNotFound)
+}
+
+func TestIndexPublishesToChannel(t *testing.T) {
+	mdChannel := make(model.MdChannel)
+	s := NewIndexService(zap.NewNop(), loadTestIndices(t), mdChannel, 10, 10)
+	assert.NoError(t, s.Start(context.Background()))
+
+	s.OnMD(tick("BTCUSD", "40000", "2021-03-01T00:00:00Z"))
+	s.OnMD(tick("XRPUSD", "1", "2021-03-01T00:00:00Z"))
+	s.OnMD(tick("ETHUSD", "2000", "2021-03-01T00:00:01Z"))
+
+	symbols := []string{(<-mdChannel).Symbol, (<-mdChannel).Symbol}
+	assert.ElementsMatch(t, []string{"TOP2EW", "BASKET"}, symbols)
+	assert.NoError(t, s.Stop(context.Background()))
+}
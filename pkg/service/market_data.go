@@ -1,31 +1,100 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
+	"github.com/matbarofex/mtz-crypto/pkg/hub"
 	"github.com/matbarofex/mtz-crypto/pkg/model"
 	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
 type MarketDataService interface {
 	GetMD(symbol string) (md model.MarketData, err error)
+	// List último precio de todos los símbolos, incluidos los derivados
+	List() (rs []model.MarketData, err error)
+	// ConsumeMD procesa en segundo plano la MD recibida hasta que se cierre el channel
 	ConsumeMD(mdChannel model.MdChannel)
+	// Drain espera a que se procese la MD pendiente, luego de cerrar el channel
+	Drain(ctx context.Context) (err error)
+	// Subscribe devuelve una suscripción a las actualizaciones ya almacenadas de
+	// los símbolos indicados (sin símbolos, de todos)
+	Subscribe(symbols ...string) hub.Subscription
+	// SubscribeAfter igual que Subscribe, omitiendo del replay inicial los
+	// precios ya recibidos hasta lastSeq (ver hub.Hub)
+	SubscribeAfter(lastSeq uint64, symbols ...string) hub.Subscription
 }
 
+// defaultHubBufferSize buffer de cada suscriptor sin WithHub
+const defaultHubBufferSize = 100
+
 type marketDataService struct {
 	logger  *zap.Logger
 	mdStore store.MarketDataStore
+	// derivedByLeg instrumentos derivados a recalcular cuando se actualiza cada símbolo
+	derivedByLeg map[string][]model.DerivedInstrument
+	observers    []MarketDataObserver
+	consumers    sync.WaitGroup
+	hub          hub.Hub
+}
+
+// MarketDataObserver recibe cada actualización de market data ya almacenada,
+// incluidos los instrumentos derivados. No debe bloquear.
+type MarketDataObserver interface {
+	OnMD(md model.MarketData)
+}
+
+// MarketDataServiceOption configuración opcional del servicio de market data
+type MarketDataServiceOption func(s *marketDataService)
+
+// WithDerivedInstruments registra instrumentos que se recalculan cada vez que
+// se actualiza alguno de sus legs
+func WithDerivedInstruments(instruments []model.DerivedInstrument) MarketDataServiceOption {
+	return func(s *marketDataService) {
+		for _, instrument := range instruments {
+			for _, leg := range instrument.Legs {
+				s.derivedByLeg[leg] = append(s.derivedByLeg[leg], instrument)
+			}
+		}
+	}
+}
+
+// WithObservers registra componentes a notificar en cada actualización
+func WithObservers(observers ...MarketDataObserver) MarketDataServiceOption {
+	return func(s *marketDataService) {
+		s.observers = append(s.observers, observers...)
+	}
+}
+
+// WithHub reemplaza el hub de suscripciones, por ejemplo para configurar la
+// política ante suscriptores lentos y registrar sus métricas
+func WithHub(h hub.Hub) MarketDataServiceOption {
+	return func(s *marketDataService) {
+		s.hub = h
+	}
 }
 
 func NewMarketDataService(
 	logger *zap.Logger,
 	mdStore store.MarketDataStore,
+	opts ...MarketDataServiceOption,
 ) MarketDataService {
-	return &marketDataService{
-		logger:  logger,
-		mdStore: mdStore,
+	s := &marketDataService{
+		logger:       logger,
+		mdStore:      mdStore,
+		derivedByLeg: make(map[string][]model.DerivedInstrument),
+		// Sin WithHub las métricas de las suscripciones no se exponen
+		hub: hub.NewHub(defaultHubBufferSize, hub.ConflatePolicy, prometheus.NewRegistry()),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 func (s *marketDataService) GetMD(symbol string) (md model.MarketData, err error) {
@@ -36,14 +105,61 @@ func (s *marketDataService) GetMD(symbol string) (md model.MarketData, err error
 	return s.mdStore.GetMD(symbol)
 }
 
+func (s *marketDataService) List() (rs []model.MarketData, err error) {
+	return s.mdStore.List()
+}
+
+func (s *marketDataService) Subscribe(symbols ...string) hub.Subscription {
+	return s.hub.Subscribe(symbols...)
+}
+
+func (s *marketDataService) SubscribeAfter(lastSeq uint64, symbols ...string) hub.Subscription {
+	return s.hub.SubscribeAfter(lastSeq, symbols...)
+}
+
 func (s *marketDataService) ConsumeMD(mdChannel model.MdChannel) {
+	s.consumers.Add(1)
+
 	go func() {
-		for md := range mdChannel {
-			s.logger.Debug("new MD received", zap.Any("md", md))
+		defer s.consumers.Done()
 
-			if err := s.mdStore.SetOrUpdateMD(md); err != nil {
-				s.logger.Error("error updating MD", zap.Any("md", md), zap.Error(err))
-			}
+		for md := range mdChannel {
+			s.processMD(md)
 		}
 	}()
 }
+
+func (s *marketDataService) Drain(ctx context.Context) (err error) {
+	done := make(chan struct{})
+	go func() {
+		s.consumers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// processMD almacena la MD recibida y recalcula los instrumentos que dependen de ella
+func (s *marketDataService) processMD(md model.MarketData) {
+	s.logger.Debug("new MD received", zap.Any("md", md))
+
+	if err := s.mdStore.SetOrUpdateMD(md); err != nil {
+		s.logger.Error("error updating MD", zap.Any("md", md), zap.Error(err))
+		return
+	}
+
+	s.notify(md)
+	s.updateDerived(md.Symbol, 0)
+}
+
+func (s *marketDataService) notify(md model.MarketData) {
+	for _, observer := range s.observers {
+		observer.OnMD(md)
+	}
+	s.hub.OnMD(md)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store/memory"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMarketDataDrain(t *testing.T) {
+	mdStore := memory.NewMarketDataStore()
+	s := NewMarketDataService(zap.NewNop(), mdStore)
+
+	mdChannel := make(model.MdChannel, 10)
+	s.ConsumeMD(mdChannel)
+
+	mdChannel <- model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("45000")}
+	mdChannel <- model.MarketData{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("3000")}
+	close(mdChannel)
+
+	// Luego de cerrar el channel se procesa todo lo pendiente
+	assert.NoError(t, s.Drain(context.Background()))
+
+	md, err := mdStore.GetMD("ETHUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, "3000", md.LastPrice.String())
+}
+
+func TestMarketDataSubscribe(t *testing.T) {
+	mdStore := memory.NewMarketDataStore()
+	s := NewMarketDataService(zap.NewNop(), mdStore, WithDerivedInstruments([]model.DerivedInstrument{
+		{Symbol: "USDBTC", Kind: model.DerivedInverse, Legs: []string{"BTCUSD"}},
+	}))
+	sub := s.Subscribe("USDBTC")
+	defer sub.Unsubscribe()
+
+	mdChannel := make(model.MdChannel, 10)
+	s.ConsumeMD(mdChannel)
+	mdChannel <- model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("50000")}
+	close(mdChannel)
+	assert.NoError(t, s.Drain(context.Background()))
+
+	// Los suscriptores reciben también los instrumentos derivados
+	md := <-sub.C()
+	assert.Equal(t, "USDBTC", md.Symbol)
+	assert.Equal(t, "0.00002", md.LastPrice.String())
+}
+
+func TestMarketDataList(t *testing.T) {
+	mdStore := memory.NewMarketDataStore()
+	s := NewMarketDataService(zap.NewNop(), mdStore, WithDerivedInstruments([]model.DerivedInstrument{
+		{Symbol: "USDBTC", Kind: model.DerivedInverse, Legs: []string{"BTCUSD"}},
+	}))
+
+	mdChannel := make(model.MdChannel, 10)
+	s.ConsumeMD(mdChannel)
+	mdChannel <- model.MarketData{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("3000")}
+	mdChannel <- model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("50000")}
+	close(mdChannel)
+	assert.NoError(t, s.Drain(context.Background()))
+
+	// Se listan ordenados por símbolo, incluidos los derivados
+	mds, err := s.List()
+	assert.NoError(t, err)
+	symbols := []string{}
+	for _, md := range mds {
+		symbols = append(symbols, md.Symbol)
+	}
+	assert.Equal(t, []string{"BTCUSD", "ETHUSD", "USDBTC"}, symbols)
+
+	_, err = s.GetMD("ADAUSD")
+	assert.ErrorIs(t, err, model.ErrSymbolNotFound)
+}
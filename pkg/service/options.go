@@ -0,0 +1,26 @@
+package service
+
+import "time"
+
+// Option configuración opcional común a varios servicios
+type Option func(o *options)
+
+type options struct {
+	now func() time.Time
+}
+
+// WithClock reemplaza la fuente de la hora actual, por ejemplo en tests
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
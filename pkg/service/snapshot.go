@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"go.uber.org/zap"
+)
+
+// SnapshotSource fuente con la que se marcan los precios restaurados del snapshot
+const SnapshotSource = "snapshot"
+
+// SnapshotService persiste el último precio de cada símbolo y lo restaura al iniciar
+type SnapshotService interface {
+	MarketDataObserver
+	// Restore carga el snapshot en el store de market data, con sus fechas originales
+	Restore() (err error)
+	// Stop persiste las actualizaciones pendientes
+	lifecycle.Component
+	// Flush persiste las actualizaciones pendientes
+	Flush() (err error)
+}
+
+type snapshotService struct {
+	logger        *zap.Logger
+	snapshotStore store.MarketDataSnapshotStore
+	mdStore       store.MarketDataStore
+	// interval intervalo de persistencia; con 0 se persiste cada actualización
+	interval time.Duration
+
+	routines lifecycle.Group
+
+	mu      sync.Mutex
+	pending map[string]model.MarketData
+}
+
+func NewSnapshotService(
+	logger *zap.Logger,
+	snapshotStore store.MarketDataSnapshotStore,
+	mdStore store.MarketDataStore,
+	interval time.Duration,
+) SnapshotService {
+	return &snapshotService{
+		logger:        logger,
+		snapshotStore: snapshotStore,
+		mdStore:       mdStore,
+		interval:      interval,
+		pending:       make(map[string]model.MarketData),
+	}
+}
+
+func (s *snapshotService) Restore() (err error) {
+	mds, err := s.snapshotStore.LoadMD()
+	if err != nil {
+		return err
+	}
+
+	for _, md := range mds {
+		md.Sources = []string{SnapshotSource}
+		if err := s.mdStore.SetOrUpdateMD(md); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("market data snapshot restored", zap.Int("symbols", len(mds)))
+
+	return nil
+}
+
+// OnMD registra la actualización. Sin intervalo configurado se persiste en el
+// momento, demorando al consumidor de market data lo que tarde la DB.
+func (s *snapshotService) OnMD(md model.MarketData) {
+	if s.interval <= 0 {
+		if err := s.snapshotStore.SaveMD([]model.MarketData{md}); err != nil {
+			s.logger.Error("error saving market data snapshot", zap.String("symbol", md.Symbol), zap.Error(err))
+		}
+		return
+	}
+
+	s.mu.Lock()
+	s.pending[md.Symbol] = md
+	s.mu.Unlock()
+}
+
+func (s *snapshotService) Start(ctx context.Context) error {
+	if s.interval <= 0 {
+		return nil
+	}
+
+	s.routines.Go(func(ctx context.Context) {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+
+			if err := s.Flush(); err != nil {
+				s.logger.Error("error saving market data snapshot", zap.Error(err))
+			}
+		}
+	})
+
+	return nil
+}
+
+func (s *snapshotService) Stop(ctx context.Context) error {
+	if err := s.routines.Stop(ctx); err != nil {
+		return err
+	}
+
+	return s.Flush()
+}
+
+func (s *snapshotService) Flush() (err error) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[string]model.MarketData)
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	mds := make([]model.MarketData, 0, len(pending))
+	for _, md := range pending {
+		mds = append(mds, md)
+	}
+	sort.Slice(mds, func(i, j int) bool { return mds[i].Symbol < mds[j].Symbol })
+
+	if err := s.snapshotStore.SaveMD(mds); err != nil {
+		// Se reintentan en el próximo flush, salvo que ya haya un precio más nuevo
+		s.mu.Lock()
+		for _, md := range mds {
+			if _, found := s.pending[md.Symbol]; !found {
+				s.pending[md.Symbol] = md
+			}
+		}
+		s.mu.Unlock()
+
+		return err
+	}
+
+	s.logger.Debug("market data snapshot saved", zap.Int("symbols", len(mds)))
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store/memory"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	ts, _ := time.Parse(time.RFC3339, "2021-09-23T12:34:56Z")
+	saved := []model.MarketData{{
+		Symbol:            "BTCUSD",
+		LastPrice:         decimal.RequireFromString("45000"),
+		LastPriceDateTime: ts,
+		Sources:           []string{"cryptonator"},
+	}}
+
+	snapshotStoreMock := new(mocks.MarketDataSnapshotStore)
+	snapshotStoreMock.On("LoadMD").Return(saved, nil)
+
+	mdStore := memory.NewMarketDataStore()
+	snapshotService := NewSnapshotService(zap.NewNop(), snapshotStoreMock, mdStore, time.Minute)
+	assert.NoError(t, snapshotService.Restore())
+
+	md, err := mdStore.GetMD("BTCUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, "45000", md.LastPrice.String())
+	assert.Equal(t, ts, md.LastPriceDateTime)
+	assert.Equal(t, []string{SnapshotSource}, md.Sources)
+}
+
+func TestSnapshotFlushKeepsLatestPrice(t *testing.T) {
+	first := model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("1")}
+	second := model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("2")}
+	other := model.MarketData{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("3")}
+
+	snapshotStoreMock := new(mocks.MarketDataSnapshotStore)
+	snapshotStoreMock.On("SaveMD", []model.MarketData{second, other}).Return(errors.New("db down")).Once()
+	snapshotStoreMock.On("SaveMD", []model.MarketData{second, other}).Return(nil).Once()
+
+	snapshotService := NewSnapshotService(zap.NewNop(), snapshotStoreMock, memory.NewMarketDataStore(), time.Minute)
+	snapshotService.OnMD(first)
+	snapshotService.OnMD(second)
+	snapshotService.OnMD(other)
+
+	// Si falla la DB se reintenta en el próximo flush
+	assert.Error(t, snapshotService.Flush())
+	assert.NoError(t, snapshotService.Flush())
+	assert.NoError(t, snapshotService.Flush())
+	snapshotStoreMock.AssertExpectations(t)
+}
+
+func TestSnapshotEveryUpdate(t *testing.T) {
+	md := model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("1")}
+
+	snapshotStoreMock := new(mocks.MarketDataSnapshotStore)
+	snapshotStoreMock.On("SaveMD", []model.MarketData{md}).Return(nil).Once()
+
+	snapshotService := NewSnapshotService(zap.NewNop(), snapshotStoreMock, memory.NewMarketDataStore(), 0)
+	snapshotService.OnMD(md)
+
+	snapshotStoreMock.AssertExpectations(t)
+}
+
+func TestSnapshotStopFlushesPending(t *testing.T) {
+	md := model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("1")}
+
+	snapshotStoreMock := new(mocks.MarketDataSnapshotStore)
+	snapshotStoreMock.On("SaveMD", []model.MarketData{md}).Return(nil).Once()
+
+	snapshotService := NewSnapshotService(zap.NewNop(), snapshotStoreMock, memory.NewMarketDataStore(), time.Hour)
+	assert.NoError(t, snapshotService.Start(context.Background()))
+	snapshotService.OnMD(md)
+
+	assert.NoError(t, snapshotService.Stop(context.Background()))
+	snapshotStoreMock.AssertExpectations(t)
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"go.uber.org/zap"
+)
+
+// SubscriptionService administra en runtime los pares que consulta cada
+// proveedor. Los cambios se persisten y se aplican al proveedor, que los toma
+// en su próximo ciclo.
+type SubscriptionService interface {
+	// Restore aplica las suscripciones persistidas a cada proveedor. Los pares
+	// configurados que no están persistidos se agregan como suscripciones; los
+	// persistidos conservan los cambios hechos en runtime (incluida la baja) y
+	// toman de la configuración sus opciones (intervalo, decimales, etc.).
+	Restore() (err error)
+	GetSubscriptions(provider string) (rs model.GetSubscriptionsResponse, err error)
+	AddSubscription(provider string, req model.AddSubscriptionRequest) (rs model.Subscription, err error)
+	RemoveSubscription(provider, symbol string) (err error)
+	PauseSubscription(provider, symbol string) (rs model.Subscription, err error)
+	ResumeSubscription(provider, symbol string) (rs model.Subscription, err error)
+}
+
+type subscriptionService struct {
+	logger            *zap.Logger
+	subscriptionStore store.SubscriptionStore
+	providers         map[string]crypto.Subscriber
+	now               func() time.Time
+
+	mu            sync.Mutex
+	subscriptions map[string]map[string]model.Subscription
+	// options opciones configuradas por par (intervalo, decimales, etc.), que
+	// se conservan al aplicar las suscripciones
+	options map[string]map[string]crypto.SymbolPair
+}
+
+func NewSubscriptionService(
+	logger *zap.Logger,
+	subscriptionStore store.SubscriptionStore,
+	providers map[string]crypto.Subscriber,
+	opts ...Option,
+) SubscriptionService {
+	o := newOptions(opts)
+
+	return &subscriptionService{
+		logger:            logger,
+		subscriptionStore: subscriptionStore,
+		providers:         providers,
+		now:               o.now,
+		subscriptions:     make(map[string]map[string]model.Subscription),
+		options:           make(map[string]map[string]crypto.SymbolPair),
+	}
+}
+
+func (s *subscriptionService) Restore() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, provider := range s.providers {
+		options := make(map[string]crypto.SymbolPair)
+		for _, pair := range provider.Pairs() {
+			options[pair.Symbol] = pair
+		}
+		s.options[name] = options
+
+		subscriptions, err := s.subscriptionStore.GetSubscriptions(name)
+		if err != nil {
+			return err
+		}
+
+		bySymbol := make(map[string]model.Subscription)
+		removed := make(map[string]bool)
+		for _, sub := range subscriptions {
+			if sub.RemovedAt != nil {
+				removed[sub.Symbol] = true
+				continue
+			}
+			bySymbol[sub.Symbol] = sub
+		}
+
+		now := s.now()
+		for _, pair := range provider.Pairs() {
+			if sub, found := bySymbol[pair.Symbol]; found {
+				if sub.ExternalSymbol != pair.ExternalSymbol {
+					s.logger.Warn("configured pair differs from stored subscription, using stored one",
+						zap.String("provider", name),
+						zap.String("symbol", pair.Symbol),
+						zap.String("configured", pair.ExternalSymbol),
+						zap.String("stored", sub.ExternalSymbol))
+				}
+				continue
+			}
+
+			// Los pares eliminados en runtime no se vuelven a agregar
+			if removed[pair.Symbol] {
+				s.logger.Info("configured pair was removed at runtime, skipping",
+					zap.String("provider", name),
+					zap.String("symbol", pair.Symbol))
+				continue
+			}
+
+			sub := model.Subscription{
+				Provider:       name,
+				Symbol:         pair.Symbol,
+				ExternalSymbol: pair.ExternalSymbol,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			}
+			if err := s.subscriptionStore.SaveSubscription(sub); err != nil {
+				return err
+			}
+			bySymbol[sub.Symbol] = sub
+		}
+
+		s.subscriptions[name] = bySymbol
+		s.apply(name)
+
+		s.logger.Info("subscriptions restored",
+			zap.String("provider", name),
+			zap.Int("subscriptions", len(bySymbol)))
+	}
+
+	return nil
+}
+
+func (s *subscriptionService) GetSubscriptions(provider string) (rs model.GetSubscriptionsResponse, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bySymbol, err := s.get(provider)
+	if err != nil {
+		return rs, err
+	}
+
+	rs.Provider = provider
+	rs.Subscriptions = make([]model.Subscription, 0, len(bySymbol))
+	for _, sub := range bySymbol {
+		rs.Subscriptions = append(rs.Subscriptions, sub)
+	}
+
+	sort.Slice(rs.Subscriptions, func(i, j int) bool {
+		return rs.Subscriptions[i].Symbol < rs.Subscriptions[j].Symbol
+	})
+
+	return rs, nil
+}
+
+func (s *subscriptionService) AddSubscription(
+	provider string,
+	req model.AddSubscriptionRequest,
+) (rs model.Subscription, err error) {
+	symbol := strings.TrimSpace(req.Symbol)
+	externalSymbol := strings.TrimSpace(req.ExternalSymbol)
+	if symbol == "" || externalSymbol == "" {
+		return rs, model.ErrInvalidSubscription
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bySymbol, err := s.get(provider)
+	if err != nil {
+		return rs, err
+	}
+
+	if _, found := bySymbol[symbol]; found {
+		return rs, model.ErrSubscriptionExists
+	}
+
+	now := s.now()
+	rs = model.Subscription{
+		Provider:       provider,
+		Symbol:         symbol,
+		ExternalSymbol: externalSymbol,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.subscriptionStore.SaveSubscription(rs); err != nil {
+		return rs, err
+	}
+
+	bySymbol[symbol] = rs
+	s.apply(provider)
+
+	s.logger.Info("subscription added",
+		zap.String("provider", provider),
+		zap.String("symbol", symbol),
+		zap.String("externalSymbol", externalSymbol))
+
+	return rs, nil
+}
+
+func (s *subscriptionService) RemoveSubscription(provider, symbol string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bySymbol, err := s.get(provider)
+	if err != nil {
+		return err
+	}
+
+	if _, found := bySymbol[symbol]; !found {
+		return model.ErrSubscriptionNotFound
+	}
+
+	if err := s.subscriptionStore.DeleteSubscription(provider, symbol); err != nil {
+		return err
+	}
+
+	delete(bySymbol, symbol)
+	s.apply(provider)
+
+	s.logger.Info("subscription removed", zap.String("provider", provider), zap.String("symbol", symbol))
+
+	return nil
+}
+
+func (s *subscriptionService) PauseSubscription(provider, symbol string) (rs model.Subscription, err error) {
+	return s.setPaused(provider, symbol, true)
+}
+
+func (s *subscriptionService) ResumeSubscription(provider, symbol string) (rs model.Subscription, err error) {
+	return s.setPaused(provider, symbol, false)
+}
+
+func (s *subscriptionService) setPaused(provider, symbol string, paused bool) (rs model.Subscription, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bySymbol, err := s.get(provider)
+	if err != nil {
+		return rs, err
+	}
+
+	rs, found := bySymbol[symbol]
+	if !found {
+		return rs, model.ErrSubscriptionNotFound
+	}
+
+	if rs.Paused == paused {
+		return rs, nil
+	}
+
+	rs.Paused = paused
+	rs.UpdatedAt = s.now()
+	if err := s.subscriptionStore.SaveSubscription(rs); err != nil {
+		return rs, err
+	}
+
+	bySymbol[symbol] = rs
+	s.apply(provider)
+
+	s.logger.Info("subscription updated",
+		zap.String("provider", provider),
+		zap.String("symbol", symbol),
+		zap.Bool("paused", paused))
+
+	return rs, nil
+}
+
+// get devuelve las suscripciones de un proveedor. Requiere s.mu.
+func (s *subscriptionService) get(provider string) (map[string]model.Subscription, error) {
+	bySymbol, found := s.subscriptions[provider]
+	if !found {
+		if _, supported := s.providers[provider]; !supported {
+			return nil, model.ErrProviderNotFound
+		}
+		bySymbol = make(map[string]model.Subscription)
+		s.subscriptions[provider] = bySymbol
+	}
+
+	return bySymbol, nil
+}
+
+// apply envía al proveedor los pares no pausados. Requiere s.mu.
+func (s *subscriptionService) apply(provider string) {
+	pairs := []crypto.SymbolPair{}
+	for _, sub := range s.subscriptions[provider] {
+		if sub.Paused {
+			continue
+		}
+
+		pair, found := s.options[provider][sub.Symbol]
+		if !found {
+			pair = crypto.SymbolPair{Symbol: sub.Symbol, Provider: provider}
+		}
+		pair.ExternalSymbol = sub.ExternalSymbol
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Symbol < pairs[j].Symbol })
+
+	s.providers[provider].SetPairs(pairs)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/crypto"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func newTestSubscriptionService(
+	subscriptionStore *mocks.SubscriptionStore,
+	provider *mocks.Subscriber,
+) *subscriptionService {
+	s := NewSubscriptionService(zap.NewNop(), subscriptionStore,
+		map[string]crypto.Subscriber{"cryptonator": provider}).(*subscriptionService)
+	s.now = func() time.Time { return time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC) }
+
+	return s
+}
+
+func TestSubscriptionRestoreSeedsConfiguredPairs(t *testing.T) {
+	subscriptionStoreMock := new(mocks.SubscriptionStore)
+	subscriptionStoreMock.On("GetSubscriptions", "cryptonator").Return(nil, nil)
+	subscriptionStoreMock.On("SaveSubscription", mock.AnythingOfType("model.Subscription")).Return(nil)
+
+	providerMock := new(mocks.Subscriber)
+	pair := crypto.SymbolPair{Symbol: "BTCUSD", ExternalSymbol: "btc-usd", Provider: "cryptonator", PollInterval: time.Second}
+	providerMock.On("Pairs").Return([]crypto.SymbolPair{pair})
+	providerMock.On("SetPairs", []crypto.SymbolPair{pair}).Return()
+
+	s := newTestSubscriptionService(subscriptionStoreMock, providerMock)
+	assert.NoError(t, s.Restore())
+
+	subscriptionStoreMock.AssertNumberOfCalls(t, "SaveSubscription", 1)
+	providerMock.AssertExpectations(t)
+}
+
+func TestSubscriptionRestoreReconcilesConfiguredPairs(t *testing.T) {
+	subscriptionStoreMock := new(mocks.SubscriptionStore)
+	subscriptionStoreMock.On("GetSubscriptions", "cryptonator").Return([]model.Subscription{
+		{Provider: "cryptonator", Symbol: "BTCUSD", ExternalSymbol: "btc-usdt"},
+		{Provider: "cryptonator", Symbol: "DOTUSD", ExternalSymbol: "dot-usd", Paused: true},
+	}, nil)
+	subscriptionStoreMock.On("SaveSubscription", mock.AnythingOfType("model.Subscription")).Return(nil)
+
+	btc := crypto.SymbolPair{Symbol: "BTCUSD", ExternalSymbol: "btc-usd", Provider: "cryptonator", PollInterval: time.Second}
+	eth := crypto.SymbolPair{Symbol: "ETHUSD", ExternalSymbol: "eth-usd", Provider: "cryptonator", MaxStaleness: time.Minute}
+	providerMock := new(mocks.Subscriber)
+	providerMock.On("Pairs").Return([]crypto.SymbolPair{btc, eth})
+	var applied []crypto.SymbolPair
+	providerMock.On("SetPairs", mock.Anything).Run(func(args mock.Arguments) {
+		applied = args.Get(0).([]crypto.SymbolPair)
+	}).Return()
+
+	s := newTestSubscriptionService(subscriptionStoreMock, providerMock)
+	assert.NoError(t, s.Restore())
+
+	// Se agrega el par nuevo de la configuración; los persistidos conservan su
+	// estado y toman las opciones configuradas
+	subscriptionStoreMock.AssertNumberOfCalls(t, "SaveSubscription", 1)
+	subscriptionStoreMock.AssertCalled(t, "SaveSubscription", mock.MatchedBy(func(sub model.Subscription) bool {
+		return sub.Symbol == "ETHUSD" && sub.ExternalSymbol == "eth-usd"
+	}))
+	btc.ExternalSymbol = "btc-usdt"
+	assert.Equal(t, []crypto.SymbolPair{btc, eth}, applied)
+}
+
+func TestSubscriptionRestoreKeepsRemovedPairs(t *testing.T) {
+	removedAt := time.Date(2021, 8, 10, 15, 0, 0, 0, time.UTC)
+	subscriptionStoreMock := new(mocks.SubscriptionStore)
+	subscriptionStoreMock.On("GetSubscriptions", "cryptonator").Return([]model.Subscription{
+		{Provider: "cryptonator", Symbol: "BTCUSD", ExternalSymbol: "btc-usd"},
+		{Provider: "cryptonator", Symbol: "ETHUSD", ExternalSymbol: "eth-usd"},
+	}, nil).Once()
+	subscriptionStoreMock.On("DeleteSubscription", "cryptonator", "BTCUSD").Return(nil)
+	// Al reiniciar el store devuelve la baja registrada
+	subscriptionStoreMock.On("GetSubscriptions", "cryptonator").Return([]model.Subscription{
+		{Provider: "cryptonator", Symbol: "BTCUSD", ExternalSymbol: "btc-usd", RemovedAt: &removedAt},
+		{Provider: "cryptonator", Symbol: "ETHUSD", ExternalSymbol: "eth-usd"},
+	}, nil)
+
+	btc := crypto.SymbolPair{Symbol: "BTCUSD", ExternalSymbol: "btc-usd", Provider: "cryptonator"}
+	eth := crypto.SymbolPair{Symbol: "ETHUSD", ExternalSymbol: "eth-usd", Provider: "cryptonator"}
+	providerMock := new(mocks.Subscriber)
+	providerMock.On("Pairs").Return([]crypto.SymbolPair{btc, eth})
+	var applied []crypto.SymbolPair
+	providerMock.On("SetPairs", mock.Anything).Run(func(args mock.Arguments) {
+		applied = args.Get(0).([]crypto.SymbolPair)
+	}).Return()
+
+	s := newTestSubscriptionService(subscriptionStoreMock, providerMock)
+	assert.NoError(t, s.Restore())
+	assert.NoError(t, s.RemoveSubscription("cryptonator", "BTCUSD"))
+	assert.Equal(t, []crypto.SymbolPair{eth}, applied)
+
+	// El par configurado eliminado no se vuelve a agregar
+	s = newTestSubscriptionService(subscriptionStoreMock, providerMock)
+	assert.NoError(t, s.Restore())
+	assert.Equal(t, []crypto.SymbolPair{eth}, applied)
+	subscriptionStoreMock.AssertNotCalled(t, "SaveSubscription", mock.Anything)
+
+	resp, err := s.GetSubscriptions("cryptonator")
+	assert.NoError(t, err)
+	assert.Len(t, resp.Subscriptions, 1)
+	assert.Equal(t, "ETHUSD", resp.Subscriptions[0].Symbol)
+}
+
+func TestSubscriptionLifecycle(t *testing.T) {
+	subscriptionStoreMock := new(mocks.SubscriptionStore)
+	subscriptionStoreMock.On("GetSubscriptions", "cryptonator").Return([]model.Subscription{
+		{Provider: "cryptonator", Symbol: "BTCUSD", ExternalSymbol: "btc-usd"},
+		{Provider: "cryptonator", Symbol: "DOTUSD", ExternalSymbol: "dot-usd", Paused: true},
+	}, nil)
+	subscriptionStoreMock.On("SaveSubscription", mock.AnythingOfType("model.Subscription")).Return(nil)
+	subscriptionStoreMock.On("DeleteSubscription", "cryptonator", "BTCUSD").Return(nil)
+
+	var applied []crypto.SymbolPair
+	providerMock := new(mocks.Subscriber)
+	providerMock.On("Pairs").Return(nil)
+	providerMock.On("SetPairs", mock.Anything).Run(func(args mock.Arguments) {
+		applied = args.Get(0).([]crypto.SymbolPair)
+	}).Return()
+
+	s := newTestSubscriptionService(subscriptionStoreMock, providerMock)
+	assert.NoError(t, s.Restore())
+	assert.Equal(t, []crypto.SymbolPair{{Symbol: "BTCUSD", ExternalSymbol: "btc-usd", Provider: "cryptonator"}}, applied)
+
+	// Alta
+	sub, err := s.AddSubscription("cryptonator", model.AddSubscriptionRequest{Symbol: "ETHUSD", ExternalSymbol: "eth-usd"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ETHUSD", sub.Symbol)
+	assert.Equal(t, []crypto.SymbolPair{
+		{Symbol: "BTCUSD", ExternalSymbol: "btc-usd", Provider: "cryptonator"},
+		{Symbol: "ETHUSD", ExternalSymbol: "eth-usd", Provider: "cryptonator"},
+	}, applied)
+
+	_, err = s.AddSubscription("cryptonator", model.AddSubscriptionRequest{Symbol: "ETHUSD", ExternalSymbol: "eth-usd"})
+	assert.ErrorIs(t, err, model.ErrSubscriptionExists)
+	_, err = s.AddSubscription("cryptonator", model.AddSubscriptionRequest{Symbol: "ADAUSD"})
+	assert.ErrorIs(t, err, model.ErrInvalidSubscription)
+	_, err = s.AddSubscription("binance", model.AddSubscriptionRequest{Symbol: "ADAUSD", ExternalSymbol: "ada-usd"})
+	assert.ErrorIs(t, err, model.ErrProviderNotFound)
+
+	// Pausa y reanudación
+	sub, err = s.PauseSubscription("cryptonator", "ETHUSD")
+	assert.NoError(t, err)
+	assert.True(t, sub.Paused)
+	assert.Equal(t, []crypto.SymbolPair{{Symbol: "BTCUSD", ExternalSymbol: "btc-usd", Provider: "cryptonator"}}, applied)
+
+	sub, err = s.ResumeSubscription("cryptonator", "DOTUSD")
+	assert.NoError(t, err)
+	assert.False(t, sub.Paused)
+	assert.Equal(t, []crypto.SymbolPair{
+		{Symbol: "BTCUSD", ExternalSymbol: "btc-usd", Provider: "cryptonator"},
+		{Symbol: "DOTUSD", ExternalSymbol: "dot-usd", Provider: "cryptonator"},
+	}, applied)
+
+	// Baja
+	assert.NoError(t, s.RemoveSubscription("cryptonator", "BTCUSD"))
+	assert.ErrorIs(t, s.RemoveSubscription("cryptonator", "BTCUSD"), model.ErrSubscriptionNotFound)
+	assert.Equal(t, []crypto.SymbolPair{{Symbol: "DOTUSD", ExternalSymbol: "dot-usd", Provider: "cryptonator"}}, applied)
+
+	resp, err := s.GetSubscriptions("cryptonator")
+	assert.NoError(t, err)
+	assert.Len(t, resp.Subscriptions, 2)
+	assert.Equal(t, "DOTUSD", resp.Subscriptions[0].Symbol)
+	assert.Equal(t, "ETHUSD", resp.Subscriptions[1].Symbol)
+	assert.True(t, resp.Subscriptions[1].Paused)
+}
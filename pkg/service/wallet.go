@@ -10,17 +10,30 @@ import (
 
 type WalletService interface {
 	GetWalletValue(req model.GetWalletValueRequest) (rs model.GetWalletValueResponse, err error)
+	// ValueWallet valúa la wallet como GetWalletValue, pero sin contar la consulta
+	// como demanda de sus símbolos. La usan las valuaciones en vivo, que se
+	// repiten con cada tick y de otro modo mantendrían calientes a sus símbolos.
+	ValueWallet(walletID string) (rs model.GetWalletValueResponse, err error)
+	// ApplyPositionChange aplica el cambio de posición. Un mensaje repetido se
+	// ignora. El evento con la wallet resultante se entrega desde el outbox.
+	ApplyPositionChange(change model.PositionChange) (err error)
 }
 
 type walletService struct {
 	mdService   MarketDataService
 	walletStore store.WalletStore
+	demand      SymbolDemand
 }
 
-func NewWalletService(walletStore store.WalletStore, mdService MarketDataService) WalletService {
+func NewWalletService(
+	walletStore store.WalletStore,
+	mdService MarketDataService,
+	demand SymbolDemand,
+) WalletService {
 	return &walletService{
 		mdService:   mdService,
 		walletStore: walletStore,
+		demand:      demand,
 	}
 }
 
@@ -30,7 +43,27 @@ func (s *walletService) GetWalletValue(req model.GetWalletValueRequest) (rs mode
 		return rs, err
 	}
 
-	rs.ID = req.ID
+	// Los símbolos valuados se consultan con más frecuencia
+	symbols := make([]string, 0, len(wallet.Items))
+	for _, item := range wallet.Items {
+		symbols = append(symbols, item.Symbol)
+	}
+	s.demand.Reference(symbols...)
+
+	return s.value(wallet)
+}
+
+func (s *walletService) ValueWallet(walletID string) (rs model.GetWalletValueResponse, err error) {
+	wallet, err := s.walletStore.GetWallet(walletID)
+	if err != nil {
+		return rs, err
+	}
+
+	return s.value(wallet)
+}
+
+func (s *walletService) value(wallet model.Wallet) (rs model.GetWalletValueResponse, err error) {
+	rs.ID = wallet.ID
 
 	var datetime time.Time
 
@@ -58,3 +91,17 @@ func (s *walletService) GetWalletValue(req model.GetWalletValueRequest) (rs mode
 
 	return rs, err
 }
+
+func (s *walletService) ApplyPositionChange(change model.PositionChange) (err error) {
+	if change.MessageID == "" || change.WalletID == "" || change.Symbol == "" ||
+		change.Quantity.Valid == change.Delta.Valid {
+		return model.ErrInvalidPositionChange
+	}
+	if change.Quantity.Valid && change.Quantity.Decimal.IsNegative() {
+		return model.ErrNegativePosition
+	}
+
+	_, err = s.walletStore.ApplyPositionChange(change)
+
+	return err
+}
@@ -49,7 +49,8 @@ func TestGetWalletValue(t *testing.T) {
 	walletStoreMock := new(mocks.WalletStore)
 	walletStoreMock.On("GetWallet", "wallet1").Return(wallet, nil)
 
-	walletService := NewWalletService(walletStoreMock, mdService)
+	demand := NewSymbolDemand(time.Minute, 1)
+	walletService := NewWalletService(walletStoreMock, mdService, demand)
 
 	req := model.GetWalletValueRequest{ID: "wallet1"}
 	resp, err := walletService.GetWalletValue(req)
@@ -58,4 +59,41 @@ func TestGetWalletValue(t *testing.T) {
 	assert.Equal(t, "wallet1", resp.ID)
 	assert.Equal(t, "0.3", resp.Value.Decimal.String())
 	assert.Equal(t, ts3, *resp.DateTime)
+	assert.True(t, demand.Hot("SYM1"))
+	assert.False(t, demand.Hot("SYM4"))
+}
+
+func TestApplyPositionChange(t *testing.T) {
+	change := model.PositionChange{
+		MessageID: "msg1",
+		WalletID:  "wallet1",
+		Symbol:    "BTCUSD",
+		Delta:     decimal.NullDecimal{Decimal: decimal.RequireFromString("0.5"), Valid: true},
+	}
+	duplicate := change
+	duplicate.MessageID = "msg0"
+
+	walletStoreMock := new(mocks.WalletStore)
+	walletStoreMock.On("ApplyPositionChange", change).Return(true, nil)
+	walletStoreMock.On("ApplyPositionChange", duplicate).Return(false, nil)
+
+	walletService := NewWalletService(walletStoreMock, nil, nil)
+
+	assert.NoError(t, walletService.ApplyPositionChange(change))
+	// Un mensaje repetido no es un error
+	assert.NoError(t, walletService.ApplyPositionChange(duplicate))
+
+	// Se indica la cantidad final o la variación, no ambas
+	invalid := change
+	invalid.Quantity = decimal.NullDecimal{Decimal: decimal.RequireFromString("2"), Valid: true}
+	assert.ErrorIs(t, walletService.ApplyPositionChange(invalid), model.ErrInvalidPositionChange)
+	assert.ErrorIs(t, walletService.ApplyPositionChange(model.PositionChange{MessageID: "msg2"}), model.ErrInvalidPositionChange)
+
+	// La cantidad final no puede ser negativa
+	negative := change
+	negative.Delta = decimal.NullDecimal{}
+	negative.Quantity = decimal.NullDecimal{Decimal: decimal.RequireFromString("-1"), Valid: true}
+	assert.ErrorIs(t, walletService.ApplyPositionChange(negative), model.ErrNegativePosition)
+
+	walletStoreMock.AssertExpectations(t)
 }
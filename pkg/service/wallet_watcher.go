@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/hub"
+	"github.com/matbarofex/mtz-crypto/pkg/lifecycle"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"go.uber.org/zap"
+)
+
+// WalletWatcher valúa nuevamente las wallets observadas cada vez que cambia el
+// precio de alguno de sus símbolos
+type WalletWatcher interface {
+	lifecycle.Component
+	Watch() WalletWatch
+}
+
+// WalletWatch conjunto de wallets observadas por un cliente. Las valuaciones se
+// envían a lo sumo una vez por intervalo; entre envíos, cada wallet se valúa una
+// sola vez con los últimos precios.
+type WalletWatch interface {
+	// Add agrega wallets; se envía de inmediato su valuación
+	Add(walletIDs ...string)
+	Remove(walletIDs ...string)
+	// C se cierra al llamar a Close
+	C() <-chan model.WalletValueUpdate
+	Close()
+}
+
+type walletWatcher struct {
+	logger          *zap.Logger
+	walletStore     store.WalletStore
+	walletService   WalletService
+	mdService       MarketDataService
+	minInterval     time.Duration
+	refreshInterval time.Duration
+	routines        lifecycle.Group
+
+	mu sync.Mutex
+	// wallets composición y observadores de cada wallet observada
+	wallets map[string]*watchedWallet
+	// bySymbol índice de las wallets observadas que contienen cada símbolo
+	bySymbol map[string]map[string]bool
+}
+
+type watchedWallet struct {
+	symbols []string
+	watches map[*walletWatch]bool
+}
+
+func NewWalletWatcher(
+	logger *zap.Logger,
+	walletStore store.WalletStore,
+	walletService WalletService,
+	mdService MarketDataService,
+	minInterval time.Duration,
+	refreshInterval time.Duration,
+) WalletWatcher {
+	return &walletWatcher{
+		logger:          logger,
+		walletStore:     walletStore,
+		walletService:   walletService,
+		mdService:       mdService,
+		minInterval:     minInterval,
+		refreshInterval: refreshInterval,
+		wallets:         make(map[string]*watchedWallet),
+		bySymbol:        make(map[string]map[string]bool),
+	}
+}
+
+// Start se suscribe a todos los símbolos y refresca periódicamente la
+// composición de las wallets observadas
+func (w *walletWatcher) Start(ctx context.Context) error {
+	w.routines.Go(func(ctx context.Context) {
+		for ctx.Err() == nil {
+			w.consume(ctx, w.mdService.Subscribe())
+		}
+	})
+	if w.refreshInterval > 0 {
+		w.routines.Go(func(ctx context.Context) {
+			for lifecycle.Sleep(ctx, w.refreshInterval) {
+				w.refresh()
+			}
+		})
+	}
+
+	return nil
+}
+
+func (w *walletWatcher) Stop(ctx context.Context) error {
+	return w.routines.Stop(ctx)
+}
+
+// consume procesa las actualizaciones hasta que se detenga el watcher o se
+// cierre la suscripción
+func (w *walletWatcher) consume(ctx context.Context, subscription hub.Subscription) {
+	defer subscription.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-subscription.C():
+			if !ok {
+				w.logger.Error("wallet watcher subscription closed, subscribing again",
+					zap.Error(subscription.Err()))
+				return
+			}
+			w.onMD(update.Symbol)
+		}
+	}
+}
+
+func (w *walletWatcher) onMD(symbol string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for walletID := range w.bySymbol[symbol] {
+		for watch := range w.wallets[walletID].watches {
+			watch.markDirty(walletID)
+		}
+	}
+}
+
+func (w *walletWatcher) Watch() WalletWatch {
+	ctx, cancel := context.WithCancel(context.Background())
+	watch := &walletWatch{
+		watcher: w,
+		ctx:     ctx,
+		cancel:  cancel,
+		ch:      make(chan model.WalletValueUpdate),
+		signal:  make(chan struct{}, 1),
+		ids:     make(map[string]bool),
+		dirty:   make(map[string]bool),
+	}
+	go watch.run()
+
+	return watch
+}
+
+// add registra la wallet en el índice. Si no se puede obtener su composición
+// queda sin símbolos hasta el próximo refresco; la valuación informa el error.
+func (w *walletWatcher) add(watch *walletWatch, walletID string) {
+	w.mu.Lock()
+	wallet, found := w.wallets[walletID]
+	if found {
+		wallet.watches[watch] = true
+	}
+	w.mu.Unlock()
+	if found {
+		return
+	}
+
+	symbols, err := w.loadSymbols(walletID)
+	if err != nil {
+		w.logger.Error("error loading watched wallet", zap.String("walletID", walletID), zap.Error(err))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if wallet, found := w.wallets[walletID]; found {
+		wallet.watches[watch] = true
+		return
+	}
+	w.wallets[walletID] = &watchedWallet{watches: map[*walletWatch]bool{watch: true}}
+	w.index(walletID, symbols)
+}
+
+func (w *walletWatcher) remove(watch *walletWatch, walletID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wallet, found := w.wallets[walletID]
+	if !found {
+		return
+	}
+
+	delete(wallet.watches, watch)
+	if len(wallet.watches) == 0 {
+		w.index(walletID, nil)
+		delete(w.wallets, walletID)
+	}
+}
+
+// refresh vuelve a leer la composición de las wallets observadas; si cambió se
+// actualiza el índice y se envía una nueva valuación
+func (w *walletWatcher) refresh() {
+	w.mu.Lock()
+	walletIDs := make([]string, 0, len(w.wallets))
+	for walletID := range w.wallets {
+		walletIDs = append(walletIDs, walletID)
+	}
+	w.mu.Unlock()
+
+	for _, walletID := range walletIDs {
+		symbols, err := w.loadSymbols(walletID)
+		if err != nil {
+			w.logger.Error("error refreshing watched wallet", zap.String("walletID", walletID), zap.Error(err))
+			continue
+		}
+
+		w.mu.Lock()
+		if wallet, found := w.wallets[walletID]; found && !reflect.DeepEqual(wallet.symbols, symbols) {
+			w.index(walletID, symbols)
+			for watch := range wallet.watches {
+				watch.markDirty(walletID)
+			}
+		}
+		w.mu.Unlock()
+	}
+}
+
+func (w *walletWatcher) loadSymbols(walletID string) ([]string, error) {
+	wallet, err := w.walletStore.GetWallet(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(wallet.Items))
+	for _, item := range wallet.Items {
+		symbols = append(symbols, item.Symbol)
+	}
+	sort.Strings(symbols)
+
+	return symbols, nil
+}
+
+// index reemplaza los símbolos de la wallet en el índice. Requiere w.mu.
+func (w *walletWatcher) index(walletID string, symbols []string) {
+	wallet := w.wallets[walletID]
+
+	for _, symbol := range wallet.symbols {
+		delete(w.bySymbol[symbol], walletID)
+		if len(w.bySymbol[symbol]) == 0 {
+			delete(w.bySymbol, symbol)
+		}
+	}
+	for _, symbol := range symbols {
+		if w.bySymbol[symbol] == nil {
+			w.bySymbol[symbol] = make(map[string]bool)
+		}
+		w.bySymbol[symbol][walletID] = true
+	}
+
+	wallet.symbols = symbols
+}
+
+// value valúa la wallet con la misma lógica que GetWalletValue, sin registrar
+// demanda de sus símbolos
+func (w *walletWatcher) value(walletID string) model.WalletValueUpdate {
+	rs, err := w.walletService.ValueWallet(walletID)
+	if err != nil {
+		w.logger.Error("error valuing watched wallet", zap.String("walletID", walletID), zap.Error(err))
+		return model.WalletValueUpdate{
+			GetWalletValueResponse: model.GetWalletValueResponse{ID: walletID},
+			Error:                  model.ErrUnexpected.Error(),
+		}
+	}
+
+	return model.WalletValueUpdate{GetWalletValueResponse: rs}
+}
+
+type walletWatch struct {
+	watcher *walletWatcher
+	ctx     context.Context
+	cancel  context.CancelFunc
+	ch      chan model.WalletValueUpdate
+	signal  chan struct{}
+
+	mu    sync.Mutex
+	ids   map[string]bool
+	dirty map[string]bool
+}
+
+func (w *walletWatch) Add(walletIDs ...string) {
+	for _, walletID := range walletIDs {
+		w.mu.Lock()
+		added := !w.ids[walletID]
+		w.ids[walletID] = true
+		w.mu.Unlock()
+
+		if added {
+			w.watcher.add(w, walletID)
+		}
+	}
+	w.markDirty(walletIDs...)
+}
+
+func (w *walletWatch) Remove(walletIDs ...string) {
+	for _, walletID := range walletIDs {
+		w.mu.Lock()
+		removed := w.ids[walletID]
+		delete(w.ids, walletID)
+		delete(w.dirty, walletID)
+		w.mu.Unlock()
+
+		if removed {
+			w.watcher.remove(w, walletID)
+		}
+	}
+}
+
+func (w *walletWatch) C() <-chan model.WalletValueUpdate {
+	return w.ch
+}
+
+func (w *walletWatch) Close() {
+	w.mu.Lock()
+	walletIDs := make([]string, 0, len(w.ids))
+	for walletID := range w.ids {
+		walletIDs = append(walletIDs, walletID)
+	}
+	w.mu.Unlock()
+
+	w.Remove(walletIDs...)
+	w.cancel()
+}
+
+func (w *walletWatch) markDirty(walletIDs ...string) {
+	w.mu.Lock()
+	for _, walletID := range walletIDs {
+		if w.ids[walletID] {
+			w.dirty[walletID] = true
+		}
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+// run envía las valuaciones de las wallets pendientes, respetando el intervalo
+// mínimo entre envíos
+func (w *walletWatch) run() {
+	defer close(w.ch)
+
+	var last time.Time
+	for {
+		select {
+		case <-w.signal:
+		case <-w.ctx.Done():
+			return
+		}
+
+		if wait := w.watcher.minInterval - time.Since(last); wait > 0 {
+			if !lifecycle.Sleep(w.ctx, wait) {
+				return
+			}
+		}
+		last = time.Now()
+
+		w.mu.Lock()
+		walletIDs := make([]string, 0, len(w.dirty))
+		for walletID := range w.dirty {
+			walletIDs = append(walletIDs, walletID)
+		}
+		w.dirty = make(map[string]bool)
+		w.mu.Unlock()
+		sort.Strings(walletIDs)
+
+		for _, walletID := range walletIDs {
+			select {
+			case w.ch <- w.watcher.value(walletID):
+			case <-w.ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/mocks"
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store/memory"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// nextValues lee las valuaciones recibidas hasta que no llegan más, en formato
+// wallet=valor
+func nextValues(t *testing.T, watch WalletWatch, wait time.Duration) []string {
+	rs := []string{}
+	for {
+		select {
+		case update, ok := <-watch.C():
+			if !ok {
+				return rs
+			}
+			value := update.Error
+			if update.Value.Valid {
+				value = update.Value.Decimal.String()
+			}
+			rs = append(rs, update.ID+"="+value)
+		case <-time.After(wait):
+			return rs
+		}
+	}
+}
+
+func TestWalletWatcher(t *testing.T) {
+	logger := zap.NewNop()
+	mdStore := memory.NewMarketDataStore()
+	mdService := NewMarketDataService(logger, mdStore)
+	_ = mdStore.SetOrUpdateMD(model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("100")})
+	_ = mdStore.SetOrUpdateMD(model.MarketData{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("10")})
+
+	walletStoreMock := new(mocks.WalletStore)
+	walletStoreMock.On("GetWallet", "wallet1").Return(model.Wallet{ID: "wallet1", Items: []model.WalletItem{
+		{Symbol: "BTCUSD", Quantity: decimal.RequireFromString("2")},
+	}}, nil)
+	walletStoreMock.On("GetWallet", "wallet2").Return(model.Wallet{ID: "wallet2", Items: []model.WalletItem{
+		{Symbol: "ETHUSD", Quantity: decimal.RequireFromString("3")},
+	}}, nil)
+	walletStoreMock.On("GetWallet", "missing").Return(model.Wallet{}, errors.New("not found"))
+	demand := NewSymbolDemand(time.Minute, 1)
+	walletService := NewWalletService(walletStoreMock, mdService, demand)
+
+	minInterval := 200 * time.Millisecond
+	w := NewWalletWatcher(logger, walletStoreMock, walletService, mdService, minInterval, 0)
+	require.NoError(t, w.Start(context.Background()))
+	defer func() { assert.NoError(t, w.Stop(context.Background())) }()
+
+	mdChannel := make(model.MdChannel, 10)
+	mdService.ConsumeMD(mdChannel)
+	defer close(mdChannel)
+	tick := func(symbol, price string) {
+		mdChannel <- model.MarketData{Symbol: symbol, LastPrice: decimal.RequireFromString(price)}
+	}
+
+	// Al agregar las wallets se envía de inmediato su valuación
+	watch := w.Watch()
+	watch.Add("wallet1", "wallet2", "missing")
+	assert.Equal(t, []string{"missing=unexpected error", "wallet1=200", "wallet2=30"}, nextValues(t, watch, 50*time.Millisecond))
+
+	// Sólo se valúan las wallets que contienen el símbolo
+	tick("ETHUSD", "11")
+	assert.Equal(t, []string{"wallet2=33"}, nextValues(t, watch, minInterval+100*time.Millisecond))
+	tick("ADAUSD", "2")
+	assert.Empty(t, nextValues(t, watch, 100*time.Millisecond))
+
+	// Dentro del intervalo mínimo los cambios se agrupan en una sola valuación
+	tick("BTCUSD", "101")
+	assert.Equal(t, []string{"wallet1=202"}, nextValues(t, watch, 10*time.Millisecond))
+	tick("BTCUSD", "102")
+	tick("BTCUSD", "103")
+	assert.Equal(t, []string{"wallet1=206"}, nextValues(t, watch, minInterval+100*time.Millisecond))
+
+	// Las wallets quitadas dejan de enviarse y salen del índice
+	watch.Remove("wallet2")
+	tick("ETHUSD", "12")
+	assert.Empty(t, nextValues(t, watch, minInterval+100*time.Millisecond))
+	ww := w.(*walletWatcher)
+	ww.mu.Lock()
+	assert.NotContains(t, ww.bySymbol, "ETHUSD")
+	ww.mu.Unlock()
+
+	// Las valuaciones en vivo no cuentan como demanda de los símbolos
+	assert.False(t, demand.Hot("BTCUSD"))
+	assert.False(t, demand.Hot("ETHUSD"))
+
+	// Al cerrar se cierra el channel y se libera el índice
+	watch.Close()
+	_, ok := <-watch.C()
+	assert.False(t, ok)
+	ww.mu.Lock()
+	assert.Empty(t, ww.wallets)
+	assert.Empty(t, ww.bySymbol)
+	ww.mu.Unlock()
+}
+
+func TestWalletWatcherRefresh(t *testing.T) {
+	logger := zap.NewNop()
+	mdStore := memory.NewMarketDataStore()
+	mdService := NewMarketDataService(logger, mdStore)
+	_ = mdStore.SetOrUpdateMD(model.MarketData{Symbol: "BTCUSD", LastPrice: decimal.RequireFromString("100")})
+	_ = mdStore.SetOrUpdateMD(model.MarketData{Symbol: "ETHUSD", LastPrice: decimal.RequireFromString("10")})
+
+	walletStoreMock := new(mocks.WalletStore)
+	walletStoreMock.On("GetWallet", "wallet1").Return(model.Wallet{ID: "wallet1", Items: []model.WalletItem{
+		{Symbol: "BTCUSD", Quantity: decimal.RequireFromString("1")},
+	}}, nil).Once()
+	walletStoreMock.On("GetWallet", "wallet1").Return(model.Wallet{ID: "wallet1", Items: []model.WalletItem{
+		{Symbol: "BTCUSD", Quantity: decimal.RequireFromString("1")},
+		{Symbol: "ETHUSD", Quantity: decimal.RequireFromString("1")},
+	}}, nil)
+	walletService := NewWalletService(walletStoreMock, mdService, NewSymbolDemand(time.Minute, 1))
+
+	w := NewWalletWatcher(logger, walletStoreMock, walletService, mdService, 0, 20*time.Millisecond)
+	require.NoError(t, w.Start(context.Background()))
+	defer func() { assert.NoError(t, w.Stop(context.Background())) }()
+
+	watch := w.Watch()
+	defer watch.Close()
+	watch.Add("wallet1")
+
+	// La valuación inicial ya usa la composición nueva; el refresco agrega ETHUSD
+	// al índice y envía la valuación una vez más
+	assert.Equal(t, []string{"wallet1=110", "wallet1=110"}, nextValues(t, watch, 100*time.Millisecond))
+	ww := w.(*walletWatcher)
+	ww.mu.Lock()
+	assert.Contains(t, ww.bySymbol["ETHUSD"], "wallet1")
+	ww.mu.Unlock()
+}
@@ -31,3 +31,11 @@ func (s *walletCacheStore) GetWallet(walletID string) (rs model.Wallet, err erro
 
 	return wallet.(model.Wallet), nil
 }
+
+// ApplyPositionChange aplica el cambio e invalida la wallet en la cache
+func (s *walletCacheStore) ApplyPositionChange(change model.PositionChange) (applied bool, err error) {
+	applied, err = s.walletStore.ApplyPositionChange(change)
+	s.cache.Delete(change.WalletID)
+
+	return applied, err
+}
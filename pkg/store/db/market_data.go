@@ -0,0 +1,78 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type marketDataSnapshotStore struct {
+	db *gorm.DB
+}
+
+// marketDataSnapshot fila de la tabla market_data_snapshots
+type marketDataSnapshot struct {
+	Symbol            string `gorm:"primaryKey"`
+	LastPrice         decimal.Decimal
+	LastPriceDateTime time.Time
+	Sources           string
+	UpdatedAt         time.Time
+}
+
+func NewMarketDataSnapshotStore(db *gorm.DB) store.MarketDataSnapshotStore {
+	return &marketDataSnapshotStore{db: db}
+}
+
+func (s *marketDataSnapshotStore) SaveMD(mds []model.MarketData) (err error) {
+	if len(mds) == 0 {
+		return nil
+	}
+
+	rows := make([]marketDataSnapshot, 0, len(mds))
+	for _, md := range mds {
+		rows = append(rows, marketDataSnapshot{
+			Symbol:            md.Symbol,
+			LastPrice:         md.LastPrice,
+			LastPriceDateTime: md.LastPriceDateTime,
+			Sources:           strings.Join(md.Sources, ","),
+		})
+	}
+
+	// Un precio más viejo no reemplaza al guardado, ya que varias réplicas
+	// pueden escribir la misma tabla
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "symbol"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_price", "last_price_date_time", "sources", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "market_data_snapshots.last_price_date_time < excluded.last_price_date_time"},
+		}},
+	}).Create(&rows).Error
+}
+
+func (s *marketDataSnapshotStore) LoadMD() (rs []model.MarketData, err error) {
+	rows := []marketDataSnapshot{}
+
+	err = s.db.Find(&rows).Error
+	if err != nil {
+		return rs, err
+	}
+
+	for _, row := range rows {
+		md := model.MarketData{
+			Symbol:            row.Symbol,
+			LastPrice:         row.LastPrice,
+			LastPriceDateTime: row.LastPriceDateTime,
+		}
+		if row.Sources != "" {
+			md.Sources = strings.Split(row.Sources, ",")
+		}
+		rs = append(rs, md)
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"sort"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"gorm.io/gorm"
+)
+
+type outboxStore struct {
+	db *gorm.DB
+}
+
+// outboxEvent fila de la tabla outbox
+type outboxEvent struct {
+	ID           int64 `gorm:"primaryKey"`
+	WalletID     string
+	Payload      []byte
+	Attempts     int
+	LastError    string
+	ClaimedUntil *time.Time
+	DeadAt       *time.Time
+	CreatedAt    time.Time
+}
+
+func (outboxEvent) TableName() string {
+	return "outbox"
+}
+
+func NewOutboxStore(db *gorm.DB) store.OutboxStore {
+	return &outboxStore{db: db}
+}
+
+// claimPendingSQL reserva el primer evento de cada wallet si no está reservado
+// ni descartado. Los descartados siguen contando como primer evento para no
+// entregar los siguientes fuera de orden. La condición sobre claimed_until se repite en el UPDATE porque
+// Postgres la vuelve a evaluar sobre la fila actualizada por otra réplica, de
+// modo que dos réplicas no reservan el mismo evento. Las fechas son las de la
+// DB, para no depender del reloj de cada réplica.
+const claimPendingSQL = `
+UPDATE outbox SET claimed_until = now() + make_interval(secs => ?)
+WHERE id IN (
+	SELECT id FROM (
+		SELECT DISTINCT ON (wallet_id) id, claimed_until, dead_at FROM outbox
+		ORDER BY wallet_id, id
+	) heads
+	WHERE dead_at IS NULL AND (claimed_until IS NULL OR claimed_until < now())
+	ORDER BY id
+	LIMIT ?
+) AND (claimed_until IS NULL OR claimed_until < now())
+RETURNING id, wallet_id, payload, attempts, created_at`
+
+func (s *outboxStore) ClaimPending(limit int, lease time.Duration) (rs []model.OutboxEvent, err error) {
+	rows := []outboxEvent{}
+	if err := s.db.Raw(claimPendingSQL, lease.Seconds(), limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+
+	rs = make([]model.OutboxEvent, 0, len(rows))
+	for _, row := range rows {
+		rs = append(rs, model.OutboxEvent{
+			ID:        row.ID,
+			WalletID:  row.WalletID,
+			Payload:   row.Payload,
+			Attempts:  row.Attempts,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+
+	return rs, nil
+}
+
+func (s *outboxStore) Delete(id int64) (err error) {
+	return s.db.Delete(&outboxEvent{}, id).Error
+}
+
+func (s *outboxStore) MarkFailed(id int64, reason string, dead bool) (err error) {
+	updates := map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": reason,
+	}
+	if dead {
+		updates["dead_at"] = gorm.Expr("now()")
+	}
+
+	return s.db.Model(&outboxEvent{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"gorm.io/gorm"
+)
+
+type processedMessageStore struct {
+	db *gorm.DB
+}
+
+func NewProcessedMessageStore(db *gorm.DB) store.ProcessedMessageStore {
+	return &processedMessageStore{db: db}
+}
+
+func (s *processedMessageStore) DeleteProcessedBefore(before time.Time) (deleted int64, err error) {
+	rs := s.db.Delete(&processedMessage{}, "created_at < ?", before)
+
+	return rs.RowsAffected, rs.Error
+}
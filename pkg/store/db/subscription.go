@@ -0,0 +1,81 @@
+package db
+
+import (
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type subscriptionStore struct {
+	db *gorm.DB
+}
+
+// subscription fila de la tabla subscriptions
+type subscription struct {
+	Provider       string `gorm:"primaryKey"`
+	Symbol         string `gorm:"primaryKey"`
+	ExternalSymbol string
+	Paused         bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	RemovedAt      *time.Time
+}
+
+func NewSubscriptionStore(db *gorm.DB) store.SubscriptionStore {
+	return &subscriptionStore{db: db}
+}
+
+func (s *subscriptionStore) GetSubscriptions(provider string) (rs []model.Subscription, err error) {
+	rows := []subscription{}
+
+	err = s.db.Order("symbol").Find(&rows, "provider = ?", provider).Error
+	if err != nil {
+		return rs, err
+	}
+
+	for _, row := range rows {
+		rs = append(rs, model.Subscription{
+			Provider:       row.Provider,
+			Symbol:         row.Symbol,
+			ExternalSymbol: row.ExternalSymbol,
+			Paused:         row.Paused,
+			CreatedAt:      row.CreatedAt,
+			UpdatedAt:      row.UpdatedAt,
+			RemovedAt:      row.RemovedAt,
+		})
+	}
+
+	return rs, nil
+}
+
+func (s *subscriptionStore) SaveSubscription(sub model.Subscription) (err error) {
+	row := subscription{
+		Provider:       sub.Provider,
+		Symbol:         sub.Symbol,
+		ExternalSymbol: sub.ExternalSymbol,
+		Paused:         sub.Paused,
+		CreatedAt:      sub.CreatedAt,
+		UpdatedAt:      sub.UpdatedAt,
+		RemovedAt:      sub.RemovedAt,
+	}
+
+	// Volver a guardar un par eliminado lo da de alta nuevamente
+	return s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "provider"}, {Name: "symbol"}},
+		DoUpdates: clause.AssignmentColumns(
+			[]string{"external_symbol", "paused", "created_at", "updated_at", "removed_at"}),
+	}).Create(&row).Error
+}
+
+// DeleteSubscription marca la baja del par en lugar de borrarlo, para que al
+// reiniciar no se vuelva a agregar desde la configuración
+func (s *subscriptionStore) DeleteSubscription(provider, symbol string) (err error) {
+	now := time.Now()
+
+	return s.db.Model(&subscription{}).
+		Where("provider = ? AND symbol = ?", provider, symbol).
+		Updates(map[string]interface{}{"removed_at": now, "updated_at": now}).Error
+}
@@ -1,17 +1,37 @@
 package db
 
 import (
+	"encoding/json"
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/events"
 	"github.com/matbarofex/mtz-crypto/pkg/model"
 	"github.com/matbarofex/mtz-crypto/pkg/store"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type walletStore struct {
-	db *gorm.DB
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// walletItem fila de la tabla wallet_items
+type walletItem struct {
+	WalletID string `gorm:"primaryKey"`
+	Symbol   string `gorm:"primaryKey"`
+	Quantity decimal.Decimal
+}
+
+// processedMessage fila de la tabla processed_messages: mensajes ya aplicados
+type processedMessage struct {
+	MessageID string `gorm:"primaryKey"`
+	CreatedAt time.Time
 }
 
 func NewWalletStore(db *gorm.DB) store.WalletStore {
-	return &walletStore{db: db}
+	return &walletStore{db: db, now: time.Now}
 }
 
 func (s *walletStore) GetWallet(walletID string) (rs model.Wallet, err error) {
@@ -27,3 +47,69 @@ func (s *walletStore) GetWallet(walletID string) (rs model.Wallet, err error) {
 
 	return rs, err
 }
+
+// ApplyPositionChange registra el mensaje, aplica el cambio y agrega el evento al
+// outbox en la misma transacción, de modo que cada mensaje se aplica una única
+// vez y su evento no se pierde. Si la cantidad resultante es negativa no se
+// aplica ni se registra el mensaje.
+func (s *walletStore) ApplyPositionChange(change model.PositionChange) (applied bool, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		rs := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&processedMessage{MessageID: change.MessageID})
+		if rs.Error != nil {
+			return rs.Error
+		}
+		if rs.RowsAffected == 0 {
+			return nil
+		}
+		applied = true
+
+		item := walletItem{WalletID: change.WalletID, Symbol: change.Symbol, Quantity: change.Quantity.Decimal}
+		quantity := clause.Assignments(map[string]interface{}{"quantity": gorm.Expr("excluded.quantity")})
+		if change.Delta.Valid {
+			item.Quantity = change.Delta.Decimal
+			quantity = clause.Assignments(map[string]interface{}{
+				"quantity": gorm.Expr("wallet_items.quantity + excluded.quantity"),
+			})
+		}
+
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "wallet_id"}, {Name: "symbol"}},
+			DoUpdates: quantity,
+		}).Create(&item).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.First(&item, "wallet_id = ? AND symbol = ?", change.WalletID, change.Symbol).Error
+		if err != nil {
+			return err
+		}
+		if item.Quantity.IsNegative() {
+			return model.ErrNegativePosition
+		}
+
+		err = tx.Delete(&walletItem{}, "wallet_id = ? AND symbol = ? AND quantity = 0",
+			change.WalletID, change.Symbol).Error
+		if err != nil {
+			return err
+		}
+
+		items := []model.WalletItem{}
+		if err := tx.Find(&items, "wallet_id = ?", change.WalletID).Error; err != nil {
+			return err
+		}
+		payload, err := json.Marshal(events.NewWalletChangedEvent(
+			model.Wallet{ID: change.WalletID, Items: items}, s.now()))
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&outboxEvent{WalletID: change.WalletID, Payload: payload}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return applied, nil
+}
@@ -1,7 +1,7 @@
 package memory
 
 import (
-	"errors"
+	"sort"
 	"sync"
 
 	"github.com/matbarofex/mtz-crypto/pkg/model"
@@ -21,7 +21,7 @@ func NewMarketDataStore() store.MarketDataStore {
 func (s *marketDataStore) GetMD(symbol string) (rs model.MarketData, err error) {
 	value, ok := s.data.Load(symbol)
 	if !ok {
-		return rs, errors.New("symbol not found")
+		return rs, model.ErrSymbolNotFound
 	}
 
 	return value.(model.MarketData), nil
@@ -32,3 +32,14 @@ func (s *marketDataStore) SetOrUpdateMD(md model.MarketData) (err error) {
 
 	return nil
 }
+
+func (s *marketDataStore) List() (rs []model.MarketData, err error) {
+	rs = []model.MarketData{}
+	s.data.Range(func(key, value interface{}) bool {
+		rs = append(rs, value.(model.MarketData))
+		return true
+	})
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Symbol < rs[j].Symbol })
+
+	return rs, nil
+}
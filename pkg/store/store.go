@@ -1,12 +1,59 @@
 package store
 
-import "github.com/matbarofex/mtz-crypto/pkg/model"
+import (
+	"time"
+
+	"github.com/matbarofex/mtz-crypto/pkg/model"
+)
 
 type WalletStore interface {
 	GetWallet(id string) (rs model.Wallet, err error)
+	// ApplyPositionChange aplica el cambio si su mensaje no se aplicó antes.
+	// Devuelve applied=false si es un mensaje repetido. En la misma transacción
+	// se agrega al outbox el evento con la composición resultante.
+	ApplyPositionChange(change model.PositionChange) (applied bool, err error)
+}
+
+// ProcessedMessageStore registro de los mensajes de posición ya aplicados
+type ProcessedMessageStore interface {
+	// DeleteProcessedBefore elimina los mensajes registrados antes de la fecha
+	DeleteProcessedBefore(before time.Time) (deleted int64, err error)
+}
+
+// OutboxStore eventos pendientes de entrega, escritos en la misma transacción
+// que el cambio que los origina
+type OutboxStore interface {
+	// ClaimPending reserva durante lease hasta limit eventos: el evento más
+	// antiguo de cada wallet, si no está reservado ni descartado. Así los eventos
+	// de una wallet se entregan en orden aunque haya varias réplicas, y una
+	// wallet que falla no demora a las demás.
+	ClaimPending(limit int, lease time.Duration) (rs []model.OutboxEvent, err error)
+	// Delete elimina un evento entregado
+	Delete(id int64) (err error)
+	// MarkFailed registra el error de entrega. El evento sigue reservado hasta el
+	// fin del lease, que actúa como espera antes del reintento. Con dead=true el
+	// evento deja de entregarse y bloquea a los siguientes de la wallet hasta que
+	// se lo elimine o se lo vuelva a habilitar.
+	MarkFailed(id int64, reason string, dead bool) (err error)
 }
 
 type MarketDataStore interface {
 	GetMD(symbol string) (rs model.MarketData, err error)
 	SetOrUpdateMD(md model.MarketData) (err error)
+	// List último precio de todos los símbolos, ordenados por símbolo
+	List() (rs []model.MarketData, err error)
+}
+
+// MarketDataSnapshotStore persistencia del último precio de cada símbolo, para
+// no arrancar con el store de market data vacío
+type MarketDataSnapshotStore interface {
+	SaveMD(mds []model.MarketData) (err error)
+	LoadMD() (rs []model.MarketData, err error)
+}
+
+// SubscriptionStore persistencia de los pares administrados en runtime
+type SubscriptionStore interface {
+	GetSubscriptions(provider string) (rs []model.Subscription, err error)
+	SaveSubscription(subscription model.Subscription) (err error)
+	DeleteSubscription(provider, symbol string) (err error)
 }
@@ -0,0 +1,8 @@
+CREATE TABLE "market_data_snapshots" (
+    "symbol" text NOT NULL,
+    "last_price" numeric NOT NULL,
+    "last_price_date_time" timestamptz NOT NULL,
+    "sources" text NOT NULL DEFAULT '',
+    "updated_at" timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT "pk_market_data_snapshots" PRIMARY KEY ("symbol")
+);
@@ -0,0 +1,10 @@
+CREATE TABLE "subscriptions" (
+    "provider" text NOT NULL,
+    "symbol" text NOT NULL,
+    "external_symbol" text NOT NULL,
+    "paused" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL DEFAULT now(),
+    "updated_at" timestamptz NOT NULL DEFAULT now(),
+    "removed_at" timestamptz NULL,
+    CONSTRAINT "pk_subscriptions" PRIMARY KEY ("provider", "symbol")
+);
@@ -0,0 +1,7 @@
+CREATE TABLE "processed_messages" (
+    "message_id" text NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT "pk_processed_messages" PRIMARY KEY ("message_id")
+);
+
+CREATE INDEX "ix_processed_messages_created_at" ON "processed_messages" ("created_at");
@@ -0,0 +1,13 @@
+CREATE TABLE "outbox" (
+    "id" bigserial NOT NULL,
+    "wallet_id" text NOT NULL,
+    "payload" bytea NOT NULL,
+    "attempts" integer NOT NULL DEFAULT 0,
+    "last_error" text NOT NULL DEFAULT '',
+    "claimed_until" timestamptz,
+    "dead_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT "pk_outbox" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "ix_outbox_wallet" ON "outbox" ("wallet_id", "id");